@@ -0,0 +1,159 @@
+package btree
+
+import "slices"
+
+// WriteBatch collects puts and deletes so that they can be applied to a tree in a single call. The zero value is an
+// empty batch ready to use. When the same key is written more than once, the last write wins.
+type WriteBatch[K any, V any] struct {
+	ops []batchOp[K, V]
+}
+
+type batchOp[K any, V any] struct {
+	item   *Item[K, V]
+	delete bool
+}
+
+// Put adds the key to the batch, to be added to the tree or to replace its value.
+func (wb *WriteBatch[K, V]) Put(key K, value V) {
+	wb.ops = append(wb.ops, batchOp[K, V]{item: newItem(key, value)})
+}
+
+// Delete adds the removal of the key to the batch. Like Tree.Delete, a key that isn't in the tree is ignored.
+func (wb *WriteBatch[K, V]) Delete(key K) {
+	var zero V
+	wb.ops = append(wb.ops, batchOp[K, V]{item: newItem(key, zero), delete: true})
+}
+
+// Len returns the number of writes in the batch, including writes to the same key.
+func (wb *WriteBatch[K, V]) Len() int {
+	return len(wb.ops)
+}
+
+// Reset empties the batch so that it can be reused.
+func (wb *WriteBatch[K, V]) Reset() {
+	wb.ops = wb.ops[:0]
+}
+
+// Apply applies all the writes of the batch to the tree. The writes are sorted by key first, so the writes to keys that
+// are next to each other share a single descent from the root, and the tree is rebalanced once on the way back up
+// instead of after every write. The batch itself isn't changed and can be applied again. Like DeleteRange, it keeps
+// NodeCount up to date by counting the nodes that are split off, merged or dropped on the way.
+//
+// The whole batch is applied before Apply returns. A Tree isn't safe for concurrent use, so there's nothing that could
+// observe it half applied. ConcurrentTree applies batches while holding its lock for the same reason.
+func (b *Tree[K, V]) Apply(wb *WriteBatch[K, V]) {
+	if len(wb.ops) == 0 {
+		return
+	}
+	ops := slices.Clone(wb.ops)
+	// The sort is stable so that the writes to the same key stay in the order they were made, and the last one of
+	// every key is the one that is kept.
+	slices.SortStableFunc(ops, func(a, c batchOp[K, V]) int {
+		return b.compare(a.item.key, c.item.key)
+	})
+	unique := ops[:0]
+	for i, op := range ops {
+		if i+1 < len(ops) && b.compare(op.item.key, ops[i+1].item.key) == 0 {
+			continue
+		}
+		unique = append(unique, op)
+	}
+
+	changed, nodes := b.mutableRoot().applyBatch(unique)
+	if changed {
+		b.nodeCount += nodes + b.repairRoot()
+		b.modifications++
+	}
+}
+
+// applyBatch applies the sorted writes, which all fall inside the subtree of the node. The writes are split between
+// the children according to the items of the node, and each child gets its part recursively. Like deleteRange, it
+// repairs the children before returning but may leave the node itself with too many or too few items for its parent to
+// repair. It reports whether any of the writes changed the subtree, which deletes of missing keys don't, and by how
+// many the number of nodes in the subtree changed.
+func (n *Node[K, V]) applyBatch(ops []batchOp[K, V]) (bool, int) {
+	if n.isLeaf() {
+		return n.applyBatchToLeaf(ops), 0
+	}
+
+	changed, nodes := false, 0
+	var deletedIndexes []int
+	for i := 0; i <= len(n.items); i++ {
+		// The writes to the child before item i are the ones with smaller keys
+		end := len(ops)
+		if i < len(n.items) {
+			end, _ = slices.BinarySearchFunc(ops, n.items[i].key, func(op batchOp[K, V], key K) int {
+				return n.config.compare(op.item.key, key)
+			})
+		}
+		if end > 0 {
+			childChanged, childNodes := n.mutableChild(i).applyBatch(ops[:end])
+			changed = changed || childChanged
+			nodes += childNodes
+		}
+		ops = ops[end:]
+
+		// A write to the key of item i itself is applied here
+		if i < len(n.items) && len(ops) > 0 && n.hasKeyAt(ops[0].item.key, i) {
+			changed = true
+			if ops[0].delete {
+				deletedIndexes = append(deletedIndexes, i)
+			} else {
+				n.items[i] = ops[0].item
+			}
+			ops = ops[1:]
+		}
+	}
+
+	// Deleted items are replaced like in removeItemFromInternal, by the biggest item of the left child. If the left
+	// child is empty, then the smallest item of the right one is used. If both are empty, then the item is removed
+	// together with one of them. They're handled from the last one so that removing a child doesn't shift the indexes
+	// of the ones that are left.
+	for j := len(deletedIndexes) - 1; j >= 0; j-- {
+		i := deletedIndexes[j]
+		left, right := n.childNodes[i], n.childNodes[i+1]
+		var popped int
+		switch {
+		case left.count > 0:
+			n.items[i], popped = n.mutableChild(i).popMax()
+		case right.count > 0:
+			n.items[i], popped = n.mutableChild(i + 1).popMin()
+		default:
+			n.items = append(n.items[:i], n.items[i+1:]...)
+			n.childNodes = append(n.childNodes[:i+1], n.childNodes[i+2:]...)
+			popped = -right.nodesInSubtree()
+		}
+		nodes += popped
+	}
+
+	nodes += n.repairChildren()
+	n.updateCount()
+	return changed, nodes
+}
+
+// applyBatchToLeaf merges the sorted writes with the sorted items of the leaf in a single pass. The leaf may end up
+// with any number of items. It reports whether any of the writes changed the leaf.
+func (n *Node[K, V]) applyBatchToLeaf(ops []batchOp[K, V]) bool {
+	merged := make([]*Item[K, V], 0, len(n.items)+len(ops))
+	changed := false
+	i := 0
+	for _, op := range ops {
+		for i < len(n.items) && n.config.compare(n.items[i].key, op.item.key) < 0 {
+			merged = append(merged, n.items[i])
+			i++
+		}
+		if n.hasKeyAt(op.item.key, i) {
+			// The existing item is either replaced or deleted
+			changed = true
+			i++
+		}
+		if !op.delete {
+			changed = true
+			merged = append(merged, op.item)
+		}
+	}
+	merged = append(merged, n.items[i:]...)
+	n.items = merged
+	n.count = len(merged)
+	return changed
+}
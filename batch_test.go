@@ -0,0 +1,119 @@
+package btree
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_TreeApplyBatch(t *testing.T) {
+	tree := newTestIntTree(10)
+
+	var wb WriteBatch[int, int]
+	wb.Put(1, 1)
+	wb.Put(4, 40)
+	wb.Delete(6)
+	wb.Delete(7)
+	wb.Put(30, 30)
+	assert.Equal(t, 5, wb.Len())
+
+	tree.Apply(&wb)
+	checkTreeInvariants(t, tree)
+
+	expected := map[int]int{0: 0, 1: 1, 2: 2, 4: 40, 8: 8, 10: 10, 12: 12, 14: 14, 16: 16, 18: 18, 30: 30}
+	assert.Equal(t, len(expected), tree.Len())
+	for key, value := range tree.All() {
+		assert.Equal(t, expected[key], value, "key %d", key)
+	}
+}
+
+func Test_TreeApplyBatchLastWriteWins(t *testing.T) {
+	tree := newTestIntTree(10)
+
+	var wb WriteBatch[int, int]
+	wb.Put(1, 1)
+	wb.Delete(1)
+	wb.Delete(2)
+	wb.Put(2, 20)
+	wb.Put(4, 40)
+	wb.Put(4, 41)
+	tree.Apply(&wb)
+
+	_, found := tree.Get(1)
+	assert.False(t, found)
+	value, _ := tree.Get(2)
+	assert.Equal(t, 20, value)
+	value, _ = tree.Get(4)
+	assert.Equal(t, 41, value)
+
+	// The batch can be reused after being reset
+	wb.Reset()
+	assert.Equal(t, 0, wb.Len())
+	wb.Delete(4)
+	tree.Apply(&wb)
+	_, found = tree.Get(4)
+	assert.False(t, found)
+}
+
+func Test_TreeApplyBatchIntoEmptyTree(t *testing.T) {
+	const numOfElements = 100000
+	tree := NewTree[int, int](minItems)
+
+	var wb WriteBatch[int, int]
+	for _, key := range rand.New(rand.NewSource(1)).Perm(numOfElements) {
+		wb.Put(key, key)
+	}
+	tree.Apply(&wb)
+	checkTreeInvariants(t, tree)
+	assert.Equal(t, numOfElements, tree.Len())
+
+	// Deleting everything empties the tree
+	wb.Reset()
+	for key := 0; key < numOfElements; key++ {
+		wb.Delete(key)
+	}
+	tree.Apply(&wb)
+	checkTreeInvariants(t, tree)
+	assert.Equal(t, 0, tree.Len())
+	assert.Equal(t, 1, tree.NodeCount())
+}
+
+func Test_TreeApplyBatchRandom(t *testing.T) {
+	for _, minItemsInNode := range []int{1, 2, 3, 8} {
+		t.Run(fmt.Sprintf("minItems=%d", minItemsInNode), func(t *testing.T) {
+			const keySpace = 2000
+			r := rand.New(rand.NewSource(int64(minItemsInNode)))
+			tree := NewTree[int, int](minItemsInNode)
+			expected := map[int]int{}
+
+			for round := 0; round < 200; round++ {
+				var wb WriteBatch[int, int]
+				// Batches are either dense around a random key or spread over the whole key space
+				from, spread := r.Intn(keySpace), keySpace
+				if round%2 == 0 {
+					spread = 50
+				}
+				for i := r.Intn(300); i > 0; i-- {
+					key := (from + r.Intn(spread)) % keySpace
+					// Most batches are write heavy so that the tree grows, and some are delete heavy
+					if r.Intn(10) < 3+round%5 {
+						wb.Delete(key)
+						delete(expected, key)
+					} else {
+						wb.Put(key, round)
+						expected[key] = round
+					}
+				}
+				tree.Apply(&wb)
+				checkTreeInvariants(t, tree)
+				require.Equal(t, len(expected), tree.Len())
+			}
+			for key, value := range tree.All() {
+				require.Equal(t, expected[key], value)
+			}
+		})
+	}
+}
@@ -0,0 +1,322 @@
+package btree
+
+import (
+	"cmp"
+	"iter"
+	"slices"
+	"sync"
+	"sync/atomic"
+)
+
+// BLinkTree is a concurrent variant of the tree based on the B-link tree of Lehman and Yao. Its nodes have a
+// different layout: the items are kept only in the leaves, the internal nodes hold only keys that separate their
+// children, and every node has a link to its right sibling and a high key, which is the smallest key that belongs in
+// the sibling rather than in the node.
+//
+//	                          20
+//	            /                               \
+//	         8,14                 ---->          26                 (high keys: 20, none)
+//	   /      |      \                      /          \
+//	1,5  --> 8,11 --> 14,17   ---->     20,23   ---->   26,29       (high keys: 8, 14, 20, 26, none)
+//
+// A split moves the upper half of a node to a new sibling and links the node to it before the parent knows about the
+// sibling. A reader that gets to the node in between and doesn't find its key there sees that the key isn't below the
+// high key of the node, and follows the link to the right instead of starting again from the root. So neither
+// readers nor writers latch a node while moving to the next one. Readers hold a single latch at a time, and a writer
+// holds at most two at a time: when moving right, and while adding the new sibling to the parent it latches the parent
+// before releasing the node that was split.
+//
+// Removing a key never merges nodes, so the links always point to nodes that are in the tree, and nodes may end up
+// with fewer than minItems items, or even empty.
+type BLinkTree[K any, V any] struct {
+	root atomic.Pointer[bLinkNode[K, V]]
+	// rootLatch is held while a new root is created, so that only one of the writers that split the root does it.
+	rootLatch sync.Mutex
+	minItems  int
+	maxItems  int
+	compare   func(a, b K) int
+	length    atomic.Int64
+}
+
+type bLinkNode[K any, V any] struct {
+	latch sync.RWMutex
+	// level is the distance of the node from the leaves, so it's 0 for the leaves.
+	level int
+	// items are the items of a leaf. Internal nodes don't have items.
+	items []*Item[K, V]
+	// keys separate the children of an internal node. The keys in childNodes[i] are >= keys[i-1] and < keys[i].
+	keys       []K
+	childNodes []*bLinkNode[K, V]
+	// highKey is the smallest key that belongs in the right sibling. The rightmost node of each level has no right
+	// sibling and no high key.
+	highKey    K
+	hasHighKey bool
+	right      *bLinkNode[K, V]
+}
+
+// NewBLinkTree creates an empty B-link tree whose nodes are split once they have more than minItems*2 items or keys.
+func NewBLinkTree[K cmp.Ordered, V any](minItems int) *BLinkTree[K, V] {
+	return NewBLinkTreeFunc[K, V](minItems, cmp.Compare[K])
+}
+
+// NewBLinkTreeFunc creates an empty B-link tree like NewBLinkTree, but orders the keys with the given comparator.
+func NewBLinkTreeFunc[K any, V any](minItems int, compare func(a, b K) int) *BLinkTree[K, V] {
+	t := &BLinkTree[K, V]{
+		minItems: minItems,
+		maxItems: minItems * 2,
+		compare:  compare,
+	}
+	t.root.Store(&bLinkNode[K, V]{})
+	return t
+}
+
+// Len returns the number of items in the tree.
+func (t *BLinkTree[K, V]) Len() int {
+	return int(t.length.Load())
+}
+
+// isRightOf reports whether the key belongs in one of the right siblings of the node.
+func (t *BLinkTree[K, V]) isRightOf(n *bLinkNode[K, V], key K) bool {
+	return n.hasHighKey && t.compare(key, n.highKey) >= 0
+}
+
+// childFor returns the child of an internal node whose keys range includes the key.
+func (t *BLinkTree[K, V]) childFor(n *bLinkNode[K, V], key K) *bLinkNode[K, V] {
+	index, found := slices.BinarySearchFunc(n.keys, key, t.compare)
+	if found {
+		// The separator itself belongs to the child on its right
+		index++
+	}
+	return n.childNodes[index]
+}
+
+// findItem returns the index of the key in the items of a leaf, or where it should be inserted.
+func (t *BLinkTree[K, V]) findItem(n *bLinkNode[K, V], key K) (int, bool) {
+	return slices.BinarySearchFunc(n.items, key, func(item *Item[K, V], key K) int {
+		return t.compare(item.key, key)
+	})
+}
+
+// moveRightShared follows the right links from a node that is latched for reading until it gets to the node whose
+// range includes the key, and returns it latched for reading. The next node is latched before the current one is
+// released, so the link can't change in between.
+func (t *BLinkTree[K, V]) moveRightShared(n *bLinkNode[K, V], key K) *bLinkNode[K, V] {
+	for t.isRightOf(n, key) {
+		next := n.right
+		next.latch.RLock()
+		n.latch.RUnlock()
+		n = next
+	}
+	return n
+}
+
+// moveRight is like moveRightShared for a node that is latched for writing.
+func (t *BLinkTree[K, V]) moveRight(n *bLinkNode[K, V], key K) *bLinkNode[K, V] {
+	for t.isRightOf(n, key) {
+		next := n.right
+		next.latch.Lock()
+		n.latch.Unlock()
+		n = next
+	}
+	return n
+}
+
+// Get returns the value stored under the given key. The boolean is false when the key isn't in the tree.
+func (t *BLinkTree[K, V]) Get(key K) (V, bool) {
+	n := t.root.Load()
+	n.latch.RLock()
+	for {
+		n = t.moveRightShared(n, key)
+		if n.level == 0 {
+			break
+		}
+		child := t.childFor(n, key)
+		// The child may be split before it's latched, in which case moveRightShared finds the key to its right
+		n.latch.RUnlock()
+		child.latch.RLock()
+		n = child
+	}
+	defer n.latch.RUnlock()
+	if index, found := t.findItem(n, key); found {
+		return n.items[index].value, true
+	}
+	var zero V
+	return zero, false
+}
+
+// findLeaf returns the leaf whose range includes the key, latched for writing, and the internal nodes that were
+// visited on the way down from the bottom up. The visited nodes may have been split since, so they're only a hint for
+// where to add the siblings of the nodes that are split.
+func (t *BLinkTree[K, V]) findLeaf(key K) (*bLinkNode[K, V], []*bLinkNode[K, V]) {
+	var visited []*bLinkNode[K, V]
+	n := t.root.Load()
+	for n.level > 0 {
+		n.latch.RLock()
+		n = t.moveRightShared(n, key)
+		child := t.childFor(n, key)
+		n.latch.RUnlock()
+		visited = append(visited, n)
+		n = child
+	}
+	n.latch.Lock()
+	slices.Reverse(visited)
+	return t.moveRight(n, key), visited
+}
+
+// Put adds a key to the tree, or replaces the value if the key is already in the tree. It returns the previous value
+// and whether it was replaced.
+//
+// If the leaf has too many items, then it's split, and the new sibling is added to the parent, which may be split as
+// well, and so on. Unlike Tree.Put, the latches of the nodes above aren't held on the way down. The parent that was
+// visited may have been split since, so the writer moves right from it until it finds the node that should point to
+// the new sibling.
+func (t *BLinkTree[K, V]) Put(key K, value V) (V, bool) {
+	n, visited := t.findLeaf(key)
+	index, found := t.findItem(n, key)
+	if found {
+		oldItem := n.items[index]
+		n.items[index] = newItem(key, value)
+		n.latch.Unlock()
+		return oldItem.value, true
+	}
+	n.items = slices.Insert(n.items, index, newItem(key, value))
+	t.length.Add(1)
+
+	for len(n.items) > t.maxItems || len(n.keys) > t.maxItems {
+		sibling, separator := t.split(n)
+		var parent *bLinkNode[K, V]
+		if len(visited) > 0 {
+			parent, visited = visited[0], visited[1:]
+		} else {
+			parent = t.parentOfRoot(n, separator, sibling)
+			if parent == nil {
+				// n was the root, so a new root was created above it
+				break
+			}
+		}
+		parent.latch.Lock()
+		parent = t.moveRight(parent, separator)
+		n.latch.Unlock()
+		childIndex, _ := slices.BinarySearchFunc(parent.keys, separator, t.compare)
+		parent.keys = slices.Insert(parent.keys, childIndex, separator)
+		parent.childNodes = slices.Insert(parent.childNodes, childIndex+1, sibling)
+		n = parent
+	}
+	n.latch.Unlock()
+	var zero V
+	return zero, false
+}
+
+// split moves the upper half of the node to a new sibling and links the node to it. It returns the sibling and the
+// separator, which is the high key of the node from now on. The node must be latched for writing. The sibling isn't
+// latched, since it can only be reached through the node until it's added to the parent.
+//
+//	 n (high key: 30)                              n (high key: 20)           sibling (high key: 30)
+//	10,15,20,25          ------>                    10,15          ---->          20,25
+func (t *BLinkTree[K, V]) split(n *bLinkNode[K, V]) (*bLinkNode[K, V], K) {
+	sibling := &bLinkNode[K, V]{
+		level:      n.level,
+		highKey:    n.highKey,
+		hasHighKey: n.hasHighKey,
+		right:      n.right,
+	}
+	var separator K
+	if n.level == 0 {
+		middle := len(n.items) / 2
+		sibling.items = slices.Clone(n.items[middle:])
+		n.items = n.items[:middle:middle]
+		separator = sibling.items[0].key
+	} else {
+		// The middle key moves up to the parent, like the middle item in Node.split
+		middle := len(n.keys) / 2
+		separator = n.keys[middle]
+		sibling.keys = slices.Clone(n.keys[middle+1:])
+		sibling.childNodes = slices.Clone(n.childNodes[middle+1:])
+		n.keys = n.keys[:middle:middle]
+		n.childNodes = n.childNodes[: middle+1 : middle+1]
+	}
+	n.highKey, n.hasHighKey = separator, true
+	n.right = sibling
+	return sibling, separator
+}
+
+// parentOfRoot returns the node that the sibling of a split node should be added to when no parent was visited on the
+// way down, which means that the node was in the top level at the time. If it's still the root, then a new root is
+// created above it and its sibling instead, and nil is returned. Otherwise, another writer already created a new root,
+// and the parent is looked for from it. The new root is created before the latch of the node is released, so the root
+// is always above the level of the node by then.
+func (t *BLinkTree[K, V]) parentOfRoot(n *bLinkNode[K, V], separator K, sibling *bLinkNode[K, V]) *bLinkNode[K, V] {
+	t.rootLatch.Lock()
+	root := t.root.Load()
+	if root == n {
+		t.root.Store(&bLinkNode[K, V]{
+			level:      n.level + 1,
+			keys:       []K{separator},
+			childNodes: []*bLinkNode[K, V]{n, sibling},
+		})
+		t.rootLatch.Unlock()
+		return nil
+	}
+	// rootLatch isn't held on the way down, since a writer that holds the latch of one of the nodes there may be
+	// waiting for it to create a new root itself.
+	t.rootLatch.Unlock()
+
+	parent := root
+	for parent.level > n.level+1 {
+		parent.latch.RLock()
+		parent = t.moveRightShared(parent, separator)
+		child := t.childFor(parent, separator)
+		parent.latch.RUnlock()
+		parent = child
+	}
+	return parent
+}
+
+// Delete removes a key from the tree. It reports whether the key was in the tree. Nodes are never merged, so only the
+// leaf is latched.
+func (t *BLinkTree[K, V]) Delete(key K) bool {
+	n, _ := t.findLeaf(key)
+	defer n.latch.Unlock()
+	index, found := t.findItem(n, key)
+	if !found {
+		return false
+	}
+	n.items = slices.Delete(n.items, index, index+1)
+	t.length.Add(-1)
+	return true
+}
+
+// All returns an iterator over all the items of the tree in ascending key order. It walks the leaves using their
+// right links and copies the items of each leaf while it's latched, so the loop body runs without holding any latch
+// and may write to the tree. Writers may change the tree during the loop, so the items of different leaves may come
+// from different points in time, but every key is returned at most once and in order.
+func (t *BLinkTree[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		n := t.root.Load()
+		for n.level > 0 {
+			n.latch.RLock()
+			child := n.childNodes[0]
+			n.latch.RUnlock()
+			n = child
+		}
+
+		var last *K
+		for n != nil {
+			n.latch.RLock()
+			items, right := slices.Clone(n.items), n.right
+			n.latch.RUnlock()
+			for _, item := range items {
+				// A split of a leaf that was already visited moves some of its items to the right
+				if last != nil && t.compare(item.key, *last) <= 0 {
+					continue
+				}
+				if !yield(item.key, item.value) {
+					return
+				}
+				key := item.key
+				last = &key
+			}
+			n = right
+		}
+	}
+}
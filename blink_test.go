@@ -0,0 +1,200 @@
+package btree
+
+import (
+	"fmt"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// checkBLinkTreeInvariants walks every level of the tree from left to right using the right links. It checks that the
+// keys are sorted and below the high key of their node, that the separators of every internal node match the high
+// keys of its children, and that the children of consecutive nodes are consecutive nodes themselves.
+func checkBLinkTreeInvariants[K any, V any](t *testing.T, tree *BLinkTree[K, V]) {
+	t.Helper()
+	items := 0
+	for first := tree.root.Load(); first != nil; {
+		var expectedChild *bLinkNode[K, V]
+		if first.level > 0 {
+			expectedChild = first.childNodes[0]
+		}
+		for n := first; n != nil; n = n.right {
+			require.Equal(t, n.hasHighKey, n.right != nil)
+			var keys []K
+			if n.level == 0 {
+				require.Empty(t, n.childNodes)
+				for _, item := range n.items {
+					keys = append(keys, item.key)
+				}
+				items += len(n.items)
+			} else {
+				require.Empty(t, n.items)
+				require.Len(t, n.childNodes, len(n.keys)+1)
+				keys = n.keys
+				for i, child := range n.childNodes {
+					require.Same(t, expectedChild, child)
+					require.Equal(t, n.level-1, child.level)
+					if i < len(n.keys) {
+						require.True(t, child.hasHighKey)
+						require.Equal(t, 0, tree.compare(child.highKey, n.keys[i]))
+					} else {
+						require.Equal(t, n.hasHighKey, child.hasHighKey)
+						if n.hasHighKey {
+							require.Equal(t, 0, tree.compare(child.highKey, n.highKey))
+						}
+					}
+					expectedChild = child.right
+				}
+			}
+			require.LessOrEqual(t, len(keys), tree.maxItems)
+			for i := range keys {
+				if i > 0 {
+					require.Less(t, tree.compare(keys[i-1], keys[i]), 0)
+				}
+				if n.hasHighKey {
+					require.Less(t, tree.compare(keys[i], n.highKey), 0)
+				}
+			}
+		}
+		require.Nil(t, expectedChild)
+		if first.level == 0 {
+			break
+		}
+		first = first.childNodes[0]
+	}
+	require.Equal(t, items, tree.Len())
+}
+
+func Test_BLinkTree(t *testing.T) {
+	tree := NewBLinkTree[int, string](minItems)
+	for i := 0; i < 100; i++ {
+		_, replaced := tree.Put(i, "a")
+		assert.False(t, replaced)
+	}
+	old, replaced := tree.Put(50, "b")
+	assert.True(t, replaced)
+	assert.Equal(t, "a", old)
+	checkBLinkTreeInvariants(t, tree)
+
+	value, found := tree.Get(50)
+	assert.True(t, found)
+	assert.Equal(t, "b", value)
+	_, found = tree.Get(100)
+	assert.False(t, found)
+
+	assert.True(t, tree.Delete(50))
+	assert.False(t, tree.Delete(50))
+	assert.Equal(t, 99, tree.Len())
+	checkBLinkTreeInvariants(t, tree)
+
+	keys := []int{}
+	for key := range tree.All() {
+		keys = append(keys, key)
+	}
+	assert.Equal(t, append(keyRange(0, 50, 1), keyRange(51, 100, 1)...), keys)
+}
+
+func Test_BLinkTreeRandom(t *testing.T) {
+	for _, minItemsInNode := range []int{1, 2, 3, 8} {
+		t.Run(fmt.Sprintf("minItems=%d", minItemsInNode), func(t *testing.T) {
+			const keySpace = 2000
+			r := rand.New(rand.NewSource(int64(minItemsInNode)))
+			tree := NewBLinkTree[int, int](minItemsInNode)
+			expected := map[int]int{}
+
+			for i := 0; i < 20000; i++ {
+				key := r.Intn(keySpace)
+				if r.Intn(3) == 0 {
+					_, found := expected[key]
+					require.Equal(t, found, tree.Delete(key))
+					delete(expected, key)
+				} else {
+					tree.Put(key, i)
+					expected[key] = i
+				}
+				if i%1000 == 0 {
+					checkBLinkTreeInvariants(t, tree)
+				}
+			}
+			checkBLinkTreeInvariants(t, tree)
+			actual := map[int]int{}
+			for key, value := range tree.All() {
+				actual[key] = value
+			}
+			require.Equal(t, expected, actual)
+		})
+	}
+}
+
+// Test_BLinkTreeConcurrent keeps a set of keys in the tree while writers add and remove other keys around them, which
+// keeps splitting the nodes that hold them. Readers that get to a node after a split moved their key to the right must
+// follow the links instead of reporting the key as missing. It's meant to be run with the race detector as well.
+func Test_BLinkTreeConcurrent(t *testing.T) {
+	for _, minItemsInNode := range []int{1, 2, 8} {
+		t.Run(fmt.Sprintf("minItems=%d", minItemsInNode), func(t *testing.T) {
+			const keySpace = 8000
+			tree := NewBLinkTree[int, int](minItemsInNode)
+			// The keys divisible by 4 are never removed
+			for key := 0; key < keySpace; key += 4 {
+				tree.Put(key, key)
+			}
+
+			var writers sync.WaitGroup
+			var done atomic.Bool
+			// Every writer owns the keys that are equal to one of these offsets modulo 8, which aren't divisible by 4
+			offsets := []int{1, 2, 3, 5, 6, 7}
+			added := make([]map[int]bool, len(offsets))
+			for w := range added {
+				added[w] = map[int]bool{}
+				writers.Add(1)
+				go func() {
+					defer writers.Done()
+					r := rand.New(rand.NewSource(int64(w)))
+					for i := 0; i < 5000; i++ {
+						key := r.Intn(keySpace/8)*8 + offsets[w]
+						if r.Intn(3) == 0 {
+							assert.Equal(t, added[w][key], tree.Delete(key))
+							delete(added[w], key)
+						} else {
+							tree.Put(key, key)
+							added[w][key] = true
+						}
+					}
+				}()
+			}
+			var readers sync.WaitGroup
+			for reader := 0; reader < 4; reader++ {
+				readers.Add(1)
+				go func() {
+					defer readers.Done()
+					r := rand.New(rand.NewSource(int64(reader)))
+					for !done.Load() {
+						key := r.Intn(keySpace/4) * 4
+						value, found := tree.Get(key)
+						assert.True(t, found, "key %d", key)
+						assert.Equal(t, key, value)
+					}
+					previous := -1
+					for key := range tree.All() {
+						assert.Less(t, previous, key)
+						previous = key
+					}
+				}()
+			}
+			writers.Wait()
+			done.Store(true)
+			readers.Wait()
+
+			checkBLinkTreeInvariants(t, tree)
+			expectedLen := keySpace / 4
+			for _, keys := range added {
+				expectedLen += len(keys)
+			}
+			assert.Equal(t, expectedLen, tree.Len())
+		})
+	}
+}
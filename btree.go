@@ -1,84 +1,195 @@
-package main
-
+// Package btree implements an in-memory B-Tree. Keys are kept sorted inside the nodes, and the tree is kept balanced
+// by splitting nodes that have too many items on insertion and by rotating or merging nodes that have too few items on
+// removal.
+package btree
+
+import (
+	"cmp"
+	"errors"
+	"slices"
+)
+
+// DefaultMinItems is the minimum number of items in a node used by trees that don't need a different fan-out.
 var DefaultMinItems = 128
 
-type Item struct {
-	key   string
-	value interface{}
+// ErrKeyNotFound is returned when an operation requires a key that isn't in the tree.
+var ErrKeyNotFound = errors.New("btree: key not found")
+
+// ErrKeyExists is returned by Insert when the key is already in the tree.
+var ErrKeyExists = errors.New("btree: key already exists")
+
+// Item is a key-value pair stored in the tree.
+type Item[K any, V any] struct {
+	key   K
+	value V
 }
 
-type Node struct {
-	bucket     *Tree
-	items      []*Item
-	childNodes []*Node
+// Node is a single node of the tree. It holds the items sorted by key and, unless it's a leaf, len(items)+1 children.
+type Node[K any, V any] struct {
+	// config is shared by all the trees that share the node, so the node only uses the settings that they all have in
+	// common. The search strategy and the node count belong to each of the trees separately and are taken from the
+	// tree the node is reached from.
+	config     *treeConfig[K]
+	owner      *owner
+	items      []*Item[K, V]
+	childNodes []*Node[K, V]
+	// count is the number of items in the subtree of the node, including its own items. It's what allows finding the
+	// rank of a key and selecting a key by its position without visiting all the items before it.
+	count int
 }
 
-type Tree struct {
-	root *Node
+// Tree is a B-Tree. Every node except the root holds between minItems and maxItems (minItems*2) items. Keys are
+// ordered by compare, which returns a negative number when a < b, zero when a == b and a positive number when a > b.
+type Tree[K any, V any] struct {
+	*treeConfig[K]
+	root   *Node[K, V]
+	search SearchStrategy
+	// nodeCount is the number of nodes in the tree. The number of items doesn't have to be kept here since the root
+	// already counts the items in its subtree.
+	nodeCount int
+	// modifications counts the writes that changed the tree. A transaction remembers it when it begins, so that it can
+	// tell on commit whether the tree was changed since then (see commitTx).
+	modifications uint64
+	// owner marks the nodes the tree may change in place. Nodes that it shares with its clones are copied first.
+	owner *owner
+}
+
+// treeConfig holds the settings of a tree that never change. A clone of the tree shares them, as well as its nodes.
+type treeConfig[K any] struct {
 	minItems int
 	maxItems int
+	compare  func(a, b K) int
 }
 
-func newItem(key string, value interface{}) *Item {
-	return &Item{
+func newItem[K any, V any](key K, value V) *Item[K, V] {
+	return &Item[K, V]{
 		key:   key,
 		value: value,
 	}
 }
 
-func newTreeWithRoot(root *Node, minItems int) *Tree {
-	bucket := &Tree{
-		root:    root,
+// Key returns the key of the item.
+func (i *Item[K, V]) Key() K {
+	return i.key
+}
+
+// Value returns the value of the item.
+func (i *Item[K, V]) Value() V {
+	return i.value
+}
+
+func newTreeWithRoot[K any, V any](root *Node[K, V], minItems int, compare func(a, b K) int) *Tree[K, V] {
+	bucket := &Tree[K, V]{
+		treeConfig: &treeConfig[K]{
+			minItems: minItems,
+			maxItems: minItems * 2,
+			compare:  compare,
+		},
+		root:  root,
+		owner: &owner{},
 	}
-	bucket.root.bucket = bucket
-	bucket.minItems = minItems
-	bucket.maxItems = minItems * 2
+	bucket.root.config = bucket.treeConfig
+	bucket.root.owner = bucket.owner
+	bucket.nodeCount = 1 // the root
 	return bucket
 }
 
-func NewTree(minItems int) *Tree {
-	return newTreeWithRoot(NewEmptyNode(), minItems)
+// NewTree creates an empty tree whose nodes hold between minItems and minItems*2 items. Keys are compared using their
+// natural order, so no comparator has to be supplied.
+func NewTree[K cmp.Ordered, V any](minItems int) *Tree[K, V] {
+	return newTreeWithRoot(newEmptyNode[K, V](), minItems, cmp.Compare[K])
+}
+
+// NewTreeFunc creates an empty tree like NewTree, but orders the keys with the given comparator. It's meant for keys
+// that don't have a natural order such as structs or byte slices. compare must return a negative number when a < b,
+// zero when a == b and a positive number when a > b.
+func NewTreeFunc[K any, V any](minItems int, compare func(a, b K) int) *Tree[K, V] {
+	return newTreeWithRoot(newEmptyNode[K, V](), minItems, compare)
 }
 
-// Put adds a key to the tree. It finds the correct node and the insertion index and adds the item. When performing the
+// Put adds a key to the tree, or replaces the value if the key is already in the tree. It returns the previous value
+// and whether it was replaced. It finds the correct node and the insertion index and adds the item. When performing the
 // search, the ancestors are returned as well. This way we can iterate over them to check which nodes were modified and
 // rebalance by splitting them accordingly. If the root has too many items, then a new root of a new layer is
 // created and the created nodes from the split are added as children.
-func (b *Tree) Put(key string, value interface{}) {
+func (b *Tree[K, V]) Put(key K, value V) (V, bool) {
+	return b.put(newItem(key, value), true)
+}
+
+// Insert adds a key to the tree like Put does, but refuses to overwrite an existing key. ErrKeyExists is returned when
+// the key is already in the tree, in which case the tree is left untouched.
+func (b *Tree[K, V]) Insert(key K, value V) error {
+	if _, found := b.put(newItem(key, value), false); found {
+		return ErrKeyExists
+	}
+	return nil
+}
+
+// put adds the item to the tree. If the key is already in the tree, the existing item is replaced in place when
+// overwrite is true and kept otherwise. Either way, the existing value is returned. Items are replaced rather than
+// updated so that an item is never modified once it's in the tree.
+func (b *Tree[K, V]) put(i *Item[K, V], overwrite bool) (V, bool) {
 	// Find the path to the node where the insertion should happen
-	i := newItem(key, value)
 	insertionIndex, nodeToInsertIn, ancestorsIndexes := b.findKey(i.key, false)
+	found := nodeToInsertIn.hasKeyAt(i.key, insertionIndex)
+	if found && !overwrite {
+		return nodeToInsertIn.items[insertionIndex].value, true
+	}
+
+	b.modifications++
+	ancestors := b.getNodes(ancestorsIndexes)
+	nodeToInsertIn = ancestors[len(ancestors)-1]
+	if found {
+		oldItem := nodeToInsertIn.items[insertionIndex]
+		nodeToInsertIn.items[insertionIndex] = i
+		return oldItem.value, true
+	}
 	// Add item to the leaf node
 	nodeToInsertIn.addItem(i, insertionIndex)
 
-	ancestors := b.getNodes(ancestorsIndexes)
+	// Every node on the path has one more item in its subtree. Splitting only moves items between siblings, so the
+	// counts of the ancestors stay correct.
+	for _, node := range ancestors {
+		node.count++
+	}
 	// Rebalance the nodes all the way up. Start From one node before the last and go all the way up. Exclude root.
 	for i := len(ancestors) - 2; i >= 0; i-- {
 		pnode := ancestors[i]
 		node := ancestors[i+1]
 		nodeIndex := ancestorsIndexes[i+1]
 		if node.isOverPopulated() {
-			pnode.split(node, nodeIndex)
+			b.nodeCount += pnode.split(node, nodeIndex)
 		}
 	}
 
 	// Handle root
 	if b.root.isOverPopulated() {
-		newRoot := NewNode(b, []*Item{}, []*Node{b.root})
-		newRoot.split(b.root, 0)
+		newRoot := newNode(b.treeConfig, b.owner, []*Item[K, V]{}, []*Node[K, V]{b.root})
+		b.nodeCount += newRoot.split(newRoot.mutableChild(0), 0) + 1
 		b.root = newRoot
 	}
+	var zero V
+	return zero, false
 }
 
-// Remove removes a key from the tree. It finds the correct node and the index to remove the item from and removes it.
-// When performing the search, the ancestors are returned as well. This way we can iterate over them to check which
-// nodes were modified and rebalance by rotating or merging the unbalanced nodes. Rotation is done first. If the
-// siblings don't have enough items, then merging occurs. If the root is without items after a split, then the root is
-// removed and the tree is one level shorter.
-func (b *Tree) Remove(key string) {
+// Remove removes a key from the tree and returns the value it held. It finds the correct node and the index to remove
+// the item from and removes it. When performing the search, the ancestors are returned as well. This way we can iterate
+// over them to check which nodes were modified and rebalance by rotating or merging the unbalanced nodes. Rotation is
+// done first. If the siblings don't have enough items, then merging occurs. If the root is without items after a
+// split, then the root is removed and the tree is one level shorter. ErrKeyNotFound is returned when the key isn't in
+// the tree, in which case the tree is left untouched.
+func (b *Tree[K, V]) Remove(key K) (V, error) {
 	// Find the path to the node where the deletion should happen
 	removeItemIndex, nodeToRemoveFrom, ancestorsIndexes := b.findKey(key, true)
+	if removeItemIndex == -1 {
+		var zero V
+		return zero, ErrKeyNotFound
+	}
+	removedItem := nodeToRemoveFrom.items[removeItemIndex]
 
+	b.modifications++
+	ancestors := b.getNodes(ancestorsIndexes)
+	nodeToRemoveFrom = ancestors[len(ancestors)-1]
 	if nodeToRemoveFrom.isLeaf() {
 		nodeToRemoveFrom.removeItemFromLeaf(removeItemIndex)
 	} else {
@@ -86,42 +197,167 @@ func (b *Tree) Remove(key string) {
 		ancestorsIndexes = append(ancestorsIndexes, affectedNodes...)
 	}
 
+	b.rebalanceAfterRemove(ancestorsIndexes)
+	return removedItem.value, nil
+}
+
+// rebalanceAfterRemove rebalances the nodes along the given breadcrumbs after an item was removed from the last of
+// them. Rotation is done first and if the siblings don't have enough items, then merging occurs.
+func (b *Tree[K, V]) rebalanceAfterRemove(ancestorsIndexes []int) {
 	ancestors := b.getNodes(ancestorsIndexes)
+	// Every node on the path has one less item in its subtree. Rotating and merging update the counts of the siblings
+	// they move items between.
+	for _, node := range ancestors {
+		node.count--
+	}
 	// Rebalance the nodes all the way up. Start From one node before the last and go all the way up. Exclude root.
 	for i := len(ancestors) - 2; i >= 0; i-- {
 		pnode := ancestors[i]
 		node := ancestors[i+1]
-		if node.isUnderPopulated() {
-			pnode.rebalanceRemove(ancestorsIndexes[i+1])
+		if node.isUnderPopulated() && pnode.rebalanceRemove(ancestorsIndexes[i+1]) {
+			// One of the nodes was merged into the other and removed
+			b.nodeCount--
 		}
 	}
-	// If the root has no items after rebalancing
+	// If the root has no items after rebalancing, then its only child is the new root. It's taken from the root
+	// itself and not from the ancestors since a merge may have removed the child that was on the path.
 	if len(b.root.items) == 0 && len(b.root.childNodes) > 0 {
-		b.root = ancestors[1]
+		b.root = b.root.childNodes[0]
+		b.nodeCount--
+	}
+}
+
+// Delete removes a key from the tree like Remove does, but treats a missing key as a no-op instead of an error. This
+// makes it safe to call repeatedly with the same key. It reports whether the key was in the tree.
+func (b *Tree[K, V]) Delete(key K) bool {
+	_, err := b.Remove(key)
+	return err == nil
+}
+
+// Len returns the number of items in the tree. It's kept by the root, so it takes O(1).
+func (b *Tree[K, V]) Len() int {
+	return b.root.count
+}
+
+// Height returns the number of levels in the tree. A tree whose root is a leaf, including an empty tree, has a height
+// of 1. All the leaves are at the same depth, so it's found by walking down the leftmost path.
+func (b *Tree[K, V]) Height() int {
+	height := 1
+	for n := b.root; !n.isLeaf(); n = n.childNodes[0] {
+		height++
+	}
+	return height
+}
+
+// NodeCount returns the number of nodes in the tree. It's kept up to date as nodes are split and merged, so it takes
+// O(1).
+func (b *Tree[K, V]) NodeCount() int {
+	return b.nodeCount
+}
+
+// Min returns the item with the smallest key. The boolean is false when the tree is empty.
+func (b *Tree[K, V]) Min() (K, V, bool) {
+	n := b.root
+	for !n.isLeaf() {
+		n = n.childNodes[0]
+	}
+	if len(n.items) == 0 {
+		var zeroKey K
+		var zeroValue V
+		return zeroKey, zeroValue, false
 	}
+	return n.items[0].key, n.items[0].value, true
 }
 
-// Find Returns an item according based on the given key by performing a binary search.
-func (b *Tree) Find(key string) *Item {
+// Max returns the item with the biggest key. The boolean is false when the tree is empty.
+func (b *Tree[K, V]) Max() (K, V, bool) {
+	n := b.root
+	for !n.isLeaf() {
+		n = n.childNodes[len(n.childNodes)-1]
+	}
+	if len(n.items) == 0 {
+		var zeroKey K
+		var zeroValue V
+		return zeroKey, zeroValue, false
+	}
+	last := n.items[len(n.items)-1]
+	return last.key, last.value, true
+}
+
+// DeleteMin removes the item with the smallest key and returns it. The smallest key is always the first item of the
+// leftmost leaf, so the leftmost spine is walked directly without comparing keys. The boolean is false when the tree is
+// empty.
+func (b *Tree[K, V]) DeleteMin() (K, V, bool) {
+	ancestorsIndexes := []int{0} // index of root
+	n := b.root
+	for !n.isLeaf() {
+		ancestorsIndexes = append(ancestorsIndexes, 0)
+		n = n.childNodes[0]
+	}
+	return b.deleteFromLeaf(n, 0, ancestorsIndexes)
+}
+
+// DeleteMax removes the item with the biggest key and returns it. Like DeleteMin, it walks the rightmost spine
+// directly. The boolean is false when the tree is empty.
+func (b *Tree[K, V]) DeleteMax() (K, V, bool) {
+	ancestorsIndexes := []int{0} // index of root
+	n := b.root
+	for !n.isLeaf() {
+		ancestorsIndexes = append(ancestorsIndexes, len(n.childNodes)-1)
+		n = n.childNodes[len(n.childNodes)-1]
+	}
+	return b.deleteFromLeaf(n, len(n.items)-1, ancestorsIndexes)
+}
+
+// deleteFromLeaf removes the item at the given index of the leaf at the end of the breadcrumbs and rebalances the tree.
+func (b *Tree[K, V]) deleteFromLeaf(leaf *Node[K, V], index int, ancestorsIndexes []int) (K, V, bool) {
+	if len(leaf.items) == 0 {
+		var zeroKey K
+		var zeroValue V
+		return zeroKey, zeroValue, false
+	}
+	b.modifications++
+	ancestors := b.getNodes(ancestorsIndexes)
+	leaf = ancestors[len(ancestors)-1]
+	removedItem := leaf.items[index]
+	leaf.removeItemFromLeaf(index)
+	b.rebalanceAfterRemove(ancestorsIndexes)
+	return removedItem.key, removedItem.value, true
+}
+
+// Find Returns an item according based on the given key by performing a binary search. The returned item is a copy, so
+// the tree isn't affected by what the caller does with it.
+func (b *Tree[K, V]) Find(key K) *Item[K, V] {
 	index, containingNode, _ := b.findKey(key, true)
 	if index == -1 {
 		return nil
 	}
-	return containingNode.items[index]
+	item := *containingNode.items[index]
+	return &item
+}
+
+// Get returns the value stored under the given key. The boolean is false when the key isn't in the tree.
+func (b *Tree[K, V]) Get(key K) (V, bool) {
+	index, containingNode, _ := b.findKey(key, true)
+	if index == -1 {
+		var zero V
+		return zero, false
+	}
+	return containingNode.items[index].value, true
 }
 
 // findKey finds the node with the key, it's index in the parent's items and a list of its ancestors (not including the
-// node itself). The parent's items and key are used later for operations such as searching, adding and removing and list
-//of ancestors is used for rebalancing. It's also known as breadcrumbs.
+// node itself). The parent's items and key are used later for operations such as searching, adding and removing and
+// list of ancestors is used for rebalancing. It's also known as breadcrumbs.
 // When the item isn't found, if exact is true, then a falsey answer is returned. If exact is false, then the index
 // where the item should have been is returned (Used for insertion)
-func (b *Tree) findKey(key string, exact bool) (int, *Node, []int) {
+func (b *Tree[K, V]) findKey(key K, exact bool) (int, *Node[K, V], []int) {
 	n := b.root
 
 	// Find the path to the node where the deletion should happen
 	ancestorsIndexes := []int{0} // index of root
 	for true {
-		wasFound, index := n.findKey(key)
+		wasFound, index := n.findKey(key, b.search)
 		if wasFound {
 			return index, n, ancestorsIndexes
 		} else {
@@ -140,38 +376,56 @@ func (b *Tree) findKey(key string, exact bool) (int, *Node, []int) {
 }
 
 // getNodes returns a list of nodes based on their indexes (the breadcrumbs) from the root
-//           p
-//       /       \
-//     a          b
-//  /     \     /   \
-// c       d   e     f
+//
+//	         p
+//	     /       \
+//	   a          b
+//	/     \     /   \
+//	c       d   e     f
+//
 // For [0,1,0] -> p,b,e
-func (b *Tree) getNodes(indexes []int) []*Node {
-	nodes := []*Node{b.root}
+//
+// The nodes are returned to be changed, so nodes that are shared with a clone are copied on the way down.
+func (b *Tree[K, V]) getNodes(indexes []int) []*Node[K, V] {
+	nodes := []*Node[K, V]{b.mutableRoot()}
 	child := b.root
 	for i := 1; i < len(indexes); i++ {
-		child = child.childNodes[indexes[i]]
+		child = child.mutableChild(indexes[i])
 		nodes = append(nodes, child)
 	}
 	return nodes
 }
 
-func NewEmptyNode() *Node {
-	return &Node{
-		items:      []*Item{},
-		childNodes: []*Node{},
+func newEmptyNode[K any, V any]() *Node[K, V] {
+	return &Node[K, V]{
+		items:      []*Item[K, V]{},
+		childNodes: []*Node[K, V]{},
+	}
+}
+
+func newNode[K any, V any](
+	config *treeConfig[K], owner *owner, items []*Item[K, V], children []*Node[K, V],
+) *Node[K, V] {
+	n := &Node[K, V]{
+		config:     config,
+		owner:      owner,
+		items:      items,
+		childNodes: children,
 	}
+	n.updateCount()
+	return n
 }
 
-func NewNode(bucket *Tree, value []*Item, childNodes []*Node) *Node {
-	return &Node{
-		bucket,
-		value,
-		childNodes,
+// updateCount recomputes the number of items in the subtree of the node from its items and the counts of its children.
+func (n *Node[K, V]) updateCount() {
+	count := len(n.items)
+	for _, child := range n.childNodes {
+		count += child.count
 	}
+	n.count = count
 }
 
-func isLast(index int, parentNode *Node) bool {
+func isLast[K any, V any](index int, parentNode *Node[K, V]) bool {
 	return index == len(parentNode.items)
 }
 
@@ -179,36 +433,37 @@ func isFirst(index int) bool {
 	return index == 0
 }
 
-func (n *Node) isLeaf() bool {
+func (n *Node[K, V]) isLeaf() bool {
 	return len(n.childNodes) == 0
 }
 
-func (n *Node) isOverPopulated() bool {
-	return len(n.items) > n.bucket.maxItems
+func (n *Node[K, V]) isOverPopulated() bool {
+	return len(n.items) > n.config.maxItems
 }
 
-func (n *Node) isUnderPopulated() bool {
-	return len(n.items) < n.bucket.minItems
+func (n *Node[K, V]) isUnderPopulated() bool {
+	return len(n.items) < n.config.minItems
 }
 
-// findKey iterates all the items and finds the key. If the key is found, then the item is returned. If the key isn't
-// found then it means we have to keep searching the tree.
-func (n *Node) findKey(key string) (bool, int) {
-	for i, existingItem := range n.items {
-		if key == existingItem.key {
-			return true, i
-		}
-
-		if key < existingItem.key {
-			return false, i
-		}
+// findKey searches the items of the node for the key using the search strategy of the tree it's reached from. If the
+// key is found, then its index is returned. If the key isn't found, then the index of the child to keep searching in is
+// returned, which is also the index the key should be inserted at.
+func (n *Node[K, V]) findKey(key K, search SearchStrategy) (bool, int) {
+	if search == LinearSearch {
+		return n.linearSearch(key)
 	}
-	return false, len(n.items)
+	return n.binarySearch(key)
+}
+
+// hasKeyAt reports whether the item at the given index holds the key. It's used after findKey to tell a found key from
+// an insertion index.
+func (n *Node[K, V]) hasKeyAt(key K, index int) bool {
+	return index < len(n.items) && n.config.compare(n.items[index].key, key) == 0
 }
 
 // addItem adds an item at a given position. If the item is in the end, then the list is appended. Otherwise, the list
 // is shifted and the item is inserted.
-func (n *Node) addItem(item *Item, insertionIndex int) int {
+func (n *Node[K, V]) addItem(item *Item[K, V], insertionIndex int) int {
 	if len(n.items) == insertionIndex { // nil or empty slice or after last element
 		n.items = append(n.items, item)
 		return insertionIndex
@@ -221,7 +476,7 @@ func (n *Node) addItem(item *Item, insertionIndex int) int {
 
 // addChild adds a child at a given position. If the child is in the end, then the list is appended. Otherwise, the list
 // is shifted and the child is inserted.
-func (n *Node) addChild(node *Node, insertionIndex int) {
+func (n *Node[K, V]) addChild(node *Node[K, V], insertionIndex int) {
 	if len(n.childNodes) == insertionIndex { // nil or empty slice or after last element
 		n.childNodes = append(n.childNodes, node)
 	}
@@ -234,76 +489,97 @@ func (n *Node) addChild(node *Node, insertionIndex int) {
 // didn't exceed the maximum number of elements. If it did, then it has to be split and rebalanced. The transformation
 // is depicted in the graph below. If it's not a leaf node, then the children has to be moved as well as shown.
 // This may leave the parent unbalanced by having too many items so rebalancing has to be checked for all the ancestors.
-// 	           n                                        n
-//                 3                                       3,6
-//	      /        \           ------>       /          |          \
-//	   a           modifiedNode            a       modifiedNode     c
-//   1,2                 4,5,6,7,8            1,2          4,5         7,8
-func (n *Node) split(modifiedNode *Node, insertionIndex int) {
-	i := 0
-	nodeSize := n.bucket.minItems
-
-	for modifiedNode.isOverPopulated() {
-		middleItem := modifiedNode.items[nodeSize]
-		var newNode *Node
-		if modifiedNode.isLeaf() {
-			newNode = NewNode(n.bucket, modifiedNode.items[nodeSize+1:], []*Node{})
-			modifiedNode.items = modifiedNode.items[:nodeSize]
-		} else {
-			newNode = NewNode(n.bucket, modifiedNode.items[nodeSize+1:], modifiedNode.childNodes[i+1:])
-			modifiedNode.items = modifiedNode.items[:nodeSize]
-			modifiedNode.childNodes = modifiedNode.childNodes[:nodeSize+1]
+// Both n and modifiedNode are changed in place, so they must not be shared with a clone (see mutableChild). It returns
+// the number of nodes that were added, for the tree to count.
+//
+//		           n                                        n
+//	                3                                       3,6
+//		      /        \           ------>       /          |          \
+//		   a           modifiedNode            a       modifiedNode     c
+//	  1,2                 4,5,6,7,8            1,2          4,5         7,8
+func (n *Node[K, V]) split(modifiedNode *Node[K, V], insertionIndex int) int {
+	if !modifiedNode.isOverPopulated() {
+		return 0
+	}
+	nodeSize := n.config.minItems
+	items, childNodes := modifiedNode.items, modifiedNode.childNodes
+
+	// modifiedNode keeps the first nodeSize items
+	modifiedNode.items = items[:nodeSize]
+	items = items[nodeSize:]
+	if !modifiedNode.isLeaf() {
+		modifiedNode.childNodes = childNodes[:nodeSize+1]
+		childNodes = childNodes[nodeSize+1:]
+	}
+	modifiedNode.updateCount()
+
+	// The rest of the items are moved to new siblings. Each sibling is preceded by a middle item that moves up to n.
+	// After a single insertion there's only one sibling, but a node that grew by more than that, like after a batch
+	// write, is split into several siblings of nodeSize items until the rest fits in one. They are added to n all at
+	// once so that n's items are shifted only once. The siblings get their own copy of the items and children. Sharing
+	// the backing arrays would let a later append to modifiedNode overwrite the sibling's items.
+	var middleItems []*Item[K, V]
+	var siblings []*Node[K, V]
+	for len(items) > 0 {
+		middleItems = append(middleItems, items[0])
+		items = items[1:]
+		size := len(items)
+		if size > n.config.maxItems {
+			size = nodeSize
 		}
-		n.addItem(middleItem, insertionIndex)
-		if len(n.childNodes) == insertionIndex+1 { // If middle of list, then move items forward
-			n.childNodes = append(n.childNodes, newNode)
-		} else {
-			n.childNodes = append(n.childNodes[:insertionIndex+1], n.childNodes[insertionIndex:]...)
-			n.childNodes[insertionIndex+1] = newNode
+		siblingChildNodes := []*Node[K, V]{}
+		if len(childNodes) > 0 {
+			siblingChildNodes = append(siblingChildNodes, childNodes[:size+1]...)
+			childNodes = childNodes[size+1:]
 		}
-
-		insertionIndex += 1
-		i += 1
-		modifiedNode = newNode
+		sibling := newNode(n.config, n.owner, append([]*Item[K, V]{}, items[:size]...), siblingChildNodes)
+		siblings = append(siblings, sibling)
+		items = items[size:]
 	}
+	n.items = slices.Insert(n.items, insertionIndex, middleItems...)
+	n.childNodes = slices.Insert(n.childNodes, insertionIndex+1, siblings...)
+	return len(siblings)
 }
 
 // rebalanceRemove rebalances the tree after a remove operation. This can be either by rotating to the right, to the
 // left or by merging. Firstly, the sibling nodes are checked to see if they have enough items for rebalancing
 // (>= minItems+1). If they don't have enough items, then merging with one of the sibling nodes occurs. This may leave
-// the parent unbalanced by having too little items so rebalancing has to be checked for all the ancestors.
-func (n *Node) rebalanceRemove(unbalancedNodeIndex int) {
+// the parent unbalanced by having too little items so rebalancing has to be checked for all the ancestors. The
+// siblings are copied before they're changed if they're shared with a clone, like the unbalanced node itself. It
+// reports whether the nodes were merged, which removes one of them.
+func (n *Node[K, V]) rebalanceRemove(unbalancedNodeIndex int) bool {
 	pNode := n
-	unbalancedNode := pNode.childNodes[unbalancedNodeIndex]
+	unbalancedNode := pNode.mutableChild(unbalancedNodeIndex)
 
 	// Right rotate
-	var leftNode *Node
+	var leftNode *Node[K, V]
 	if unbalancedNodeIndex != 0 {
 		leftNode = pNode.childNodes[unbalancedNodeIndex-1]
-		if len(leftNode.items) > n.bucket.minItems {
-			rotateRight(leftNode, pNode, unbalancedNode, unbalancedNodeIndex)
-			return
+		if len(leftNode.items) > n.config.minItems {
+			rotateRight(pNode.mutableChild(unbalancedNodeIndex-1), pNode, unbalancedNode, unbalancedNodeIndex)
+			return false
 		}
 	}
 
 	// Left Balance
-	var rightNode *Node
+	var rightNode *Node[K, V]
 	if unbalancedNodeIndex != len(pNode.childNodes)-1 {
 		rightNode = pNode.childNodes[unbalancedNodeIndex+1]
-		if len(rightNode.items) > n.bucket.minItems {
-			rotateLeft(unbalancedNode, pNode, rightNode, unbalancedNodeIndex)
-			return
+		if len(rightNode.items) > n.config.minItems {
+			rotateLeft(unbalancedNode, pNode, pNode.mutableChild(unbalancedNodeIndex+1), unbalancedNodeIndex)
+			return false
 		}
 	}
 
 	merge(pNode, unbalancedNodeIndex)
+	return true
 }
 
-func (n *Node) removeItemFromLeaf(index int) {
+func (n *Node[K, V]) removeItemFromLeaf(index int) {
 	n.items = append(n.items[:index], n.items[index+1:]...)
 }
 
-func (n *Node) removeItemFromInternal(index int) []int {
+func (n *Node[K, V]) removeItemFromInternal(index int) []int {
 	// Take element before inorder (The biggest element from the left branch), put it in the removed index and remove
 	// it from the original node.
 	//          p
@@ -315,10 +591,10 @@ func (n *Node) removeItemFromInternal(index int) []int {
 	affectedNodes := make([]int, 0)
 	affectedNodes = append(affectedNodes, index)
 
-	aNode := n.childNodes[index]
+	aNode := n.mutableChild(index)
 	for !aNode.isLeaf() {
-		traversingIndex := len(n.childNodes) - 1
-		aNode = n.childNodes[traversingIndex]
+		traversingIndex := len(aNode.childNodes) - 1
+		aNode = aNode.mutableChild(traversingIndex)
 		affectedNodes = append(affectedNodes, traversingIndex)
 	}
 
@@ -327,7 +603,7 @@ func (n *Node) removeItemFromInternal(index int) []int {
 	return affectedNodes
 }
 
-func rotateRight(aNode, pNode, bNode *Node, bNodeIndex int) {
+func rotateRight[K any, V any](aNode, pNode, bNode *Node[K, V], bNodeIndex int) {
 	// 	           p                                    p
 	//                 4                                    3
 	//	      /        \           ------>         /          \
@@ -347,17 +623,21 @@ func rotateRight(aNode, pNode, bNode *Node, bNodeIndex int) {
 	pNode.items[pNodeItemIndex] = aNodeItem
 
 	// Assign parent item to b and make it first
-	bNode.items = append([]*Item{pNodeItem}, bNode.items...)
+	bNode.items = append([]*Item[K, V]{pNodeItem}, bNode.items...)
 
 	// If it's a inner leaf then move children as well.
+	movedCount := 1
 	if !aNode.isLeaf() {
 		childNodeToShift := aNode.childNodes[len(aNode.childNodes)-1]
 		aNode.childNodes = aNode.childNodes[:len(aNode.childNodes)-1]
-		bNode.childNodes = append([]*Node{childNodeToShift}, bNode.childNodes...)
+		bNode.childNodes = append([]*Node[K, V]{childNodeToShift}, bNode.childNodes...)
+		movedCount += childNodeToShift.count
 	}
+	aNode.count -= movedCount
+	bNode.count += movedCount
 }
 
-func rotateLeft(aNode, pNode, bNode *Node, bNodeIndex int) {
+func rotateLeft[K any, V any](aNode, pNode, bNode *Node[K, V], bNodeIndex int) {
 	// 	           p                                     p
 	//                 2                                     3
 	//	      /        \           ------>         /          \
@@ -380,14 +660,18 @@ func rotateLeft(aNode, pNode, bNode *Node, bNodeIndex int) {
 	aNode.items = append(aNode.items, pNodeItem)
 
 	// If it's a inner leaf then move children as well.
+	movedCount := 1
 	if !bNode.isLeaf() {
 		childNodeToShift := bNode.childNodes[0]
 		bNode.childNodes = bNode.childNodes[1:]
 		aNode.childNodes = append(aNode.childNodes, childNodeToShift)
+		movedCount += childNodeToShift.count
 	}
+	bNode.count -= movedCount
+	aNode.count += movedCount
 }
 
-func merge(pNode *Node, unbalancedNodeIndex int) {
+func merge[K any, V any](pNode *Node[K, V], unbalancedNodeIndex int) {
 	unbalancedNode := pNode.childNodes[unbalancedNodeIndex]
 	if unbalancedNodeIndex == 0 {
 		// 	               p                                     p
@@ -395,7 +679,7 @@ func merge(pNode *Node, unbalancedNodeIndex int) {
 		//	      /        |       \       ------>         /          \
 		//  a(unbalanced)   b           c                     a            c
 		//   1             3,4          6,7                 1,2,3,4        6,7
-		aNode := unbalancedNode
+		aNode := pNode.mutableChild(unbalancedNodeIndex)
 		bNode := pNode.childNodes[unbalancedNodeIndex+1]
 
 		// Take the item from the parent, remove it and add it to the unbalanced node
@@ -409,6 +693,7 @@ func merge(pNode *Node, unbalancedNodeIndex int) {
 		if !bNode.isLeaf() {
 			aNode.childNodes = append(aNode.childNodes, bNode.childNodes...)
 		}
+		aNode.count += 1 + bNode.count
 	} else {
 		// 	               p                                     p
 		//                    3,5                                    5
@@ -416,7 +701,7 @@ func merge(pNode *Node, unbalancedNodeIndex int) {
 		//           a   b(unbalanced)   c                    a            c
 		//          1,2         4        6,7                 1,2,3,4         6,7
 		bNode := unbalancedNode
-		aNode := pNode.childNodes[unbalancedNodeIndex-1]
+		aNode := pNode.mutableChild(unbalancedNodeIndex - 1)
 
 		// Take the item from the parent, remove it and add it to the unbalanced node
 		pNodeItem := pNode.items[unbalancedNodeIndex-1]
@@ -425,8 +710,9 @@ func merge(pNode *Node, unbalancedNodeIndex int) {
 
 		aNode.items = append(aNode.items, bNode.items...)
 		pNode.childNodes = append(pNode.childNodes[:unbalancedNodeIndex], pNode.childNodes[unbalancedNodeIndex+1:]...)
-		if !aNode.isLeaf() {
-			bNode.childNodes = append(aNode.childNodes, bNode.childNodes...)
+		if !bNode.isLeaf() {
+			aNode.childNodes = append(aNode.childNodes, bNode.childNodes...)
 		}
+		aNode.count += 1 + bNode.count
 	}
 }
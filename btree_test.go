@@ -1,8 +1,10 @@
-package main
+package btree
 
 import (
 	"github.com/stretchr/testify/require"
+	"math/rand"
 	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -11,24 +13,24 @@ import (
 const minItems = 2
 const mockNumberOfElements = 10
 
-func (n *Node) addChildNode(child *Node) *Node {
-	child.bucket = n.bucket
+func (n *Node[K, V]) addChildNode(child *Node[K, V]) *Node[K, V] {
+	child.config = n.config
 	n.childNodes = append(n.childNodes, child)
 	return n
 }
 
-func areTreesEqual(t *testing.T, t1, t2 *Tree) {
+func areTreesEqual[K any, V any](t *testing.T, t1, t2 *Tree[K, V]) {
 	areTreesEqualHelper(t, t1.root, t2.root)
 }
 
-func areNodesEqual(t *testing.T, n1, n2 *Node) {
+func areNodesEqual[K any, V any](t *testing.T, n1, n2 *Node[K, V]) {
 	for i := 0; i < len(n1.items); i++ {
 		assert.Equal(t, n1.items[i].key, n2.items[i].key)
 		assert.Equal(t, n1.items[i].value, n2.items[i].value)
 	}
 }
 
-func areTreesEqualHelper(t *testing.T, n1, n2 *Node) {
+func areTreesEqualHelper[K any, V any](t *testing.T, n1, n2 *Node[K, V]) {
 	require.Equal(t, len(n1.items), len(n2.items))
 	require.Equal(t, len(n1.childNodes), len(n2.childNodes))
 
@@ -39,26 +41,27 @@ func areTreesEqualHelper(t *testing.T, n1, n2 *Node) {
 	}
 }
 
-func createTestMockTree() *Tree {
-	root := NewEmptyNode()
-	root.addItems("2", "5")
+func createTestMockTree() *Tree[string, string] {
+	root := newEmptyNode[string, string]()
+	addItems(root, "2", "5")
+	tree := newTreeWithRoot(root, minItems, strings.Compare)
 
-	child0 := NewEmptyNode()
-	child0.addItems("0", "1")
+	child0 := newEmptyNode[string, string]()
+	addItems(child0, "0", "1")
 	root.addChildNode(child0)
 
-	child1 := NewEmptyNode()
-	child1.addItems("3", "4")
+	child1 := newEmptyNode[string, string]()
+	addItems(child1, "3", "4")
 	root.addChildNode(child1)
 
-	child2 := NewEmptyNode()
-	child2.addItems("6", "7", "8", "9")
+	child2 := newEmptyNode[string, string]()
+	addItems(child2, "6", "7", "8", "9")
 	root.addChildNode(child2)
 
-	return newTreeWithRoot(root, minItems)
+	return tree
 }
 
-func (n *Node) addItems(keys ...string) *Node {
+func addItems(n *Node[string, string], keys ...string) *Node[string, string] {
 	for _, key := range keys {
 		n.items = append(n.items, newItem(key, key))
 	}
@@ -66,35 +69,35 @@ func (n *Node) addItems(keys ...string) *Node {
 }
 
 func Test_BucketAddSingle(t *testing.T) {
-	bucket := NewTree(minItems)
+	bucket := NewTree[string, string](minItems)
 	value := "0"
 	bucket.Put(value, value)
 
-	root := NewEmptyNode()
-	root.addItems("0")
-	expectedbucket := &Tree{root: root}
+	root := newEmptyNode[string, string]()
+	addItems(root, "0")
+	expectedbucket := &Tree[string, string]{root: root}
 	areTreesEqual(t, expectedbucket, bucket)
 }
 
 func Test_BucketRemoveFromRootSingleElement(t *testing.T) {
-	bucket := NewTree(minItems)
+	bucket := NewTree[string, string](minItems)
 	value := "0"
 	id := "0"
 	bucket.Put(id, value)
 
 	// Tree is balanced
-	root := NewEmptyNode()
-	root.addItems("0")
-	expectedbucket := newTreeWithRoot(root, minItems)
+	root := newEmptyNode[string, string]()
+	addItems(root, "0")
+	expectedbucket := newTreeWithRoot(root, minItems, strings.Compare)
 	areTreesEqual(t, expectedbucket, bucket)
 
 	bucket.Remove(id)
-	expectedbucketAfterRemoval := NewTree(minItems)
+	expectedbucketAfterRemoval := NewTree[string, string](minItems)
 	areTreesEqual(t, expectedbucketAfterRemoval, bucket)
 }
 
 func Test_BucketAddMultiple(t *testing.T) {
-	bucket := NewTree(minItems)
+	bucket := NewTree[string, string](minItems)
 	numOfElements := mockNumberOfElements
 	for i := 0; i < numOfElements; i++ {
 		istr := strconv.Itoa(i)
@@ -106,34 +109,34 @@ func Test_BucketAddMultiple(t *testing.T) {
 }
 
 func Test_BucketAddAndRebalanceSplit(t *testing.T) {
-	root := NewEmptyNode()
-	root.addItems("4")
-	bucket := newTreeWithRoot(root, minItems)
+	root := newEmptyNode[string, string]()
+	addItems(root, "4")
+	bucket := newTreeWithRoot(root, minItems, strings.Compare)
 
-	child0 := NewEmptyNode()
-	child0.addItems("0", "1", "2", "3")
+	child0 := newEmptyNode[string, string]()
+	addItems(child0, "0", "1", "2", "3")
 	root.addChildNode(child0)
 
-	child1 := NewEmptyNode()
-	child1.addItems("5", "6", "7", "8")
+	child1 := newEmptyNode[string, string]()
+	addItems(child1, "5", "6", "7", "8")
 	root.addChildNode(child1)
 
-	bucket.Put("9","9")
+	bucket.Put("9", "9")
 
-	expectedroot := NewEmptyNode()
-	expectedroot.addItems("4", "7")
-	expectedbucket := newTreeWithRoot(expectedroot, minItems)
+	expectedroot := newEmptyNode[string, string]()
+	addItems(expectedroot, "4", "7")
+	expectedbucket := newTreeWithRoot(expectedroot, minItems, strings.Compare)
 
-	expectedchild0 := NewEmptyNode()
-	expectedchild0.addItems("0", "1", "2", "3")
+	expectedchild0 := newEmptyNode[string, string]()
+	addItems(expectedchild0, "0", "1", "2", "3")
 	expectedroot.addChildNode(expectedchild0)
 
-	expectedchild1 := NewEmptyNode()
-	expectedchild1.addItems("5", "6")
+	expectedchild1 := newEmptyNode[string, string]()
+	addItems(expectedchild1, "5", "6")
 	expectedroot.addChildNode(expectedchild1)
 
-	expectedchild2 := NewEmptyNode()
-	expectedchild2.addItems("8", "9")
+	expectedchild2 := newEmptyNode[string, string]()
+	addItems(expectedchild2, "8", "9")
 	expectedroot.addChildNode(expectedchild2)
 
 	// Tree is balanced
@@ -141,34 +144,34 @@ func Test_BucketAddAndRebalanceSplit(t *testing.T) {
 }
 
 func Test_BucketSplitAndMerge(t *testing.T) {
-	root := NewEmptyNode()
-	root.addItems("4")
-	bucket := newTreeWithRoot(root, minItems)
+	root := newEmptyNode[string, string]()
+	addItems(root, "4")
+	bucket := newTreeWithRoot(root, minItems, strings.Compare)
 
-	child0 := NewEmptyNode()
-	child0.addItems("0", "1", "2", "3")
+	child0 := newEmptyNode[string, string]()
+	addItems(child0, "0", "1", "2", "3")
 	root.addChildNode(child0)
 
-	child1 := NewEmptyNode()
-	child1.addItems("5", "6", "7", "8")
+	child1 := newEmptyNode[string, string]()
+	addItems(child1, "5", "6", "7", "8")
 	root.addChildNode(child1)
 
 	bucket.Put("9", "9")
 
-	expectedroot := NewEmptyNode()
-	expectedroot.addItems("4", "7")
-	expectedbucket := newTreeWithRoot(expectedroot, minItems)
+	expectedroot := newEmptyNode[string, string]()
+	addItems(expectedroot, "4", "7")
+	expectedbucket := newTreeWithRoot(expectedroot, minItems, strings.Compare)
 
-	expectedchild0 := NewEmptyNode()
-	expectedchild0.addItems("0", "1", "2", "3")
+	expectedchild0 := newEmptyNode[string, string]()
+	addItems(expectedchild0, "0", "1", "2", "3")
 	expectedroot.addChildNode(expectedchild0)
 
-	expectedchild1 := NewEmptyNode()
-	expectedchild1.addItems("5", "6")
+	expectedchild1 := newEmptyNode[string, string]()
+	addItems(expectedchild1, "5", "6")
 	expectedroot.addChildNode(expectedchild1)
 
-	expectedchild2 := NewEmptyNode()
-	expectedchild2.addItems("8", "9")
+	expectedchild2 := newEmptyNode[string, string]()
+	addItems(expectedchild2, "8", "9")
 	expectedroot.addChildNode(expectedchild2)
 
 	// Tree is balanced
@@ -176,16 +179,16 @@ func Test_BucketSplitAndMerge(t *testing.T) {
 
 	bucket.Remove("9")
 
-	expectedroot = NewEmptyNode()
-	expectedroot.addItems("4")
-	expectedbucket = newTreeWithRoot(expectedroot, minItems)
+	expectedroot = newEmptyNode[string, string]()
+	addItems(expectedroot, "4")
+	expectedbucket = newTreeWithRoot(expectedroot, minItems, strings.Compare)
 
-	expectedchild0 = NewEmptyNode()
-	expectedchild0.addItems("0", "1", "2", "3")
+	expectedchild0 = newEmptyNode[string, string]()
+	addItems(expectedchild0, "0", "1", "2", "3")
 	expectedroot.addChildNode(expectedchild0)
 
-	expectedchild1 = NewEmptyNode()
-	expectedchild1.addItems("5", "6", "7", "8")
+	expectedchild1 = newEmptyNode[string, string]()
+	addItems(expectedchild1, "5", "6", "7", "8")
 	expectedroot.addChildNode(expectedchild1)
 
 	areTreesEqual(t, expectedbucket, bucket)
@@ -193,7 +196,7 @@ func Test_BucketSplitAndMerge(t *testing.T) {
 }
 
 func Test_BucketRemoveFromRootWithoutRebalance(t *testing.T) {
-	bucket := NewTree(minItems)
+	bucket := NewTree[string, string](minItems)
 	for i := 0; i < mockNumberOfElements; i++ {
 		istr := strconv.Itoa(i)
 		bucket.Put(istr, istr)
@@ -202,20 +205,20 @@ func Test_BucketRemoveFromRootWithoutRebalance(t *testing.T) {
 	// Tree is balanced
 	areTreesEqual(t, createTestMockTree(), bucket)
 
-	expectedroot := NewEmptyNode()
-	expectedroot.addItems("2", "5")
-	expectedTree := newTreeWithRoot(expectedroot, minItems)
+	expectedroot := newEmptyNode[string, string]()
+	addItems(expectedroot, "2", "5")
+	expectedTree := newTreeWithRoot(expectedroot, minItems, strings.Compare)
 
-	child0 := NewEmptyNode()
-	child0.addItems("0", "1")
+	child0 := newEmptyNode[string, string]()
+	addItems(child0, "0", "1")
 	expectedroot.addChildNode(child0)
 
-	child1 := NewEmptyNode()
-	child1.addItems("3", "4")
+	child1 := newEmptyNode[string, string]()
+	addItems(child1, "3", "4")
 	expectedroot.addChildNode(child1)
 
-	child2 := NewEmptyNode()
-	child2.addItems("6", "8", "9")
+	child2 := newEmptyNode[string, string]()
+	addItems(child2, "6", "8", "9")
 	expectedroot.addChildNode(child2)
 
 	// Remove an element
@@ -224,36 +227,36 @@ func Test_BucketRemoveFromRootWithoutRebalance(t *testing.T) {
 }
 
 func Test_BucketRemoveFromRootAndRotateLeft(t *testing.T) {
-	mockRoot := NewEmptyNode()
-	mockRoot.addItems("2", "5")
-	mockTree := newTreeWithRoot(mockRoot, minItems)
+	mockRoot := newEmptyNode[string, string]()
+	addItems(mockRoot, "2", "5")
+	mockTree := newTreeWithRoot(mockRoot, minItems, strings.Compare)
 
-	mockChild0 := NewEmptyNode()
-	mockChild0.addItems("0", "1")
+	mockChild0 := newEmptyNode[string, string]()
+	addItems(mockChild0, "0", "1")
 	mockRoot.addChildNode(mockChild0)
 
-	mockChild1 := NewEmptyNode()
-	mockChild1.addItems("3", "4")
+	mockChild1 := newEmptyNode[string, string]()
+	addItems(mockChild1, "3", "4")
 	mockRoot.addChildNode(mockChild1)
 
-	mockChild2 := NewEmptyNode()
-	mockChild2.addItems("6", "7", "8")
+	mockChild2 := newEmptyNode[string, string]()
+	addItems(mockChild2, "6", "7", "8")
 	mockRoot.addChildNode(mockChild2)
 
-	expectedRoot := NewEmptyNode()
-	expectedRoot.addItems("2", "6")
-	expectedTree := newTreeWithRoot(expectedRoot, minItems)
+	expectedRoot := newEmptyNode[string, string]()
+	addItems(expectedRoot, "2", "6")
+	expectedTree := newTreeWithRoot(expectedRoot, minItems, strings.Compare)
 
-	expectedChild0 := NewEmptyNode()
-	expectedChild0.addItems("0", "1")
+	expectedChild0 := newEmptyNode[string, string]()
+	addItems(expectedChild0, "0", "1")
 	expectedRoot.addChildNode(expectedChild0)
 
-	expectedChild1 := NewEmptyNode()
-	expectedChild1.addItems("3", "4")
+	expectedChild1 := newEmptyNode[string, string]()
+	addItems(expectedChild1, "3", "4")
 	expectedRoot.addChildNode(expectedChild1)
 
-	expectedChild2 := NewEmptyNode()
-	expectedChild2.addItems("7", "8")
+	expectedChild2 := newEmptyNode[string, string]()
+	addItems(expectedChild2, "7", "8")
 	expectedRoot.addChildNode(expectedChild2)
 
 	// Remove an element
@@ -262,36 +265,36 @@ func Test_BucketRemoveFromRootAndRotateLeft(t *testing.T) {
 }
 
 func Test_BucketRemoveFromRootAndRotateRight(t *testing.T) {
-	mockRoot := NewEmptyNode()
-	mockRoot.addItems("3", "6")
-	mockTree := newTreeWithRoot(mockRoot, minItems)
+	mockRoot := newEmptyNode[string, string]()
+	addItems(mockRoot, "3", "6")
+	mockTree := newTreeWithRoot(mockRoot, minItems, strings.Compare)
 
-	mockChild0 := NewEmptyNode()
-	mockChild0.addItems("0", "1", "2")
+	mockChild0 := newEmptyNode[string, string]()
+	addItems(mockChild0, "0", "1", "2")
 	mockRoot.addChildNode(mockChild0)
 
-	mockChild1 := NewEmptyNode()
-	mockChild1.addItems("4", "5")
+	mockChild1 := newEmptyNode[string, string]()
+	addItems(mockChild1, "4", "5")
 	mockRoot.addChildNode(mockChild1)
 
-	mockChild2 := NewEmptyNode()
-	mockChild2.addItems("7", "8")
+	mockChild2 := newEmptyNode[string, string]()
+	addItems(mockChild2, "7", "8")
 	mockRoot.addChildNode(mockChild2)
 
-	expectedRoot := NewEmptyNode()
-	expectedRoot.addItems("2", "5")
-	expectedTree := newTreeWithRoot(expectedRoot, minItems)
+	expectedRoot := newEmptyNode[string, string]()
+	addItems(expectedRoot, "2", "5")
+	expectedTree := newTreeWithRoot(expectedRoot, minItems, strings.Compare)
 
-	expectedChild0 := NewEmptyNode()
-	expectedChild0.addItems("0", "1")
+	expectedChild0 := newEmptyNode[string, string]()
+	addItems(expectedChild0, "0", "1")
 	expectedRoot.addChildNode(expectedChild0)
 
-	expectedChild1 := NewEmptyNode()
-	expectedChild1.addItems("3", "4")
+	expectedChild1 := newEmptyNode[string, string]()
+	addItems(expectedChild1, "3", "4")
 	expectedRoot.addChildNode(expectedChild1)
 
-	expectedChild2 := NewEmptyNode()
-	expectedChild2.addItems("7", "8")
+	expectedChild2 := newEmptyNode[string, string]()
+	addItems(expectedChild2, "7", "8")
 	expectedRoot.addChildNode(expectedChild2)
 
 	// Remove an element
@@ -302,32 +305,32 @@ func Test_BucketRemoveFromRootAndRotateRight(t *testing.T) {
 // Test_BucketRemoveFromRootAndRebalanceMergeToUnbalanced tests when the unbalanced node is the most left one so the
 // merge has to happen from the right node into the unbalanced node
 func Test_BucketRemoveFromRootAndRebalanceMergeToUnbalanced(t *testing.T) {
-	mockRoot := NewEmptyNode()
-	mockRoot.addItems("2", "5")
-	mockTree := newTreeWithRoot(mockRoot, minItems)
+	mockRoot := newEmptyNode[string, string]()
+	addItems(mockRoot, "2", "5")
+	mockTree := newTreeWithRoot(mockRoot, minItems, strings.Compare)
 
-	mockChild0 := NewEmptyNode()
-	mockChild0.addItems("0", "1")
+	mockChild0 := newEmptyNode[string, string]()
+	addItems(mockChild0, "0", "1")
 	mockRoot.addChildNode(mockChild0)
 
-	mockChild1 := NewEmptyNode()
-	mockChild1.addItems("3", "4")
+	mockChild1 := newEmptyNode[string, string]()
+	addItems(mockChild1, "3", "4")
 	mockRoot.addChildNode(mockChild1)
 
-	mockChild2 := NewEmptyNode()
-	mockChild2.addItems("6", "7")
+	mockChild2 := newEmptyNode[string, string]()
+	addItems(mockChild2, "6", "7")
 	mockRoot.addChildNode(mockChild2)
 
-	expectedRoot := NewEmptyNode()
-	expectedRoot.addItems("5")
-	expectedTree := newTreeWithRoot(expectedRoot, minItems)
+	expectedRoot := newEmptyNode[string, string]()
+	addItems(expectedRoot, "5")
+	expectedTree := newTreeWithRoot(expectedRoot, minItems, strings.Compare)
 
-	expectedChild0 := NewEmptyNode()
-	expectedChild0.addItems("0", "1", "3", "4")
+	expectedChild0 := newEmptyNode[string, string]()
+	addItems(expectedChild0, "0", "1", "3", "4")
 	expectedRoot.addChildNode(expectedChild0)
 
-	expectedChild1 := NewEmptyNode()
-	expectedChild1.addItems("6", "7")
+	expectedChild1 := newEmptyNode[string, string]()
+	addItems(expectedChild1, "6", "7")
 	expectedRoot.addChildNode(expectedChild1)
 
 	// Remove an element
@@ -335,105 +338,104 @@ func Test_BucketRemoveFromRootAndRebalanceMergeToUnbalanced(t *testing.T) {
 	areTreesEqual(t, expectedTree, mockTree)
 }
 
-// Test_BucketRemoveFromRootAndRebalanceMergeFromUnbalanced tests when the unbalanced node is not the most left one so the
-// merge has to happen from the unbalanced node to the node left to it
+// Test_BucketRemoveFromRootAndRebalanceMergeFromUnbalanced tests when the unbalanced node is not the most left one so
+// the merge has to happen from the unbalanced node to the node left to it
 func Test_BucketRemoveFromRootAndRebalanceMergeFromUnbalanced(t *testing.T) {
-	mockRoot := NewEmptyNode()
-	mockRoot.addItems("2", "5")
-	mockTree := newTreeWithRoot(mockRoot, minItems)
+	mockRoot := newEmptyNode[string, string]()
+	addItems(mockRoot, "2", "5")
+	mockTree := newTreeWithRoot(mockRoot, minItems, strings.Compare)
 
-	mockChild0 := NewEmptyNode()
-	mockChild0.addItems("0", "1")
+	mockChild0 := newEmptyNode[string, string]()
+	addItems(mockChild0, "0", "1")
 	mockRoot.addChildNode(mockChild0)
 
-	mockChild1 := NewEmptyNode()
-	mockChild1.addItems("3", "4")
+	mockChild1 := newEmptyNode[string, string]()
+	addItems(mockChild1, "3", "4")
 	mockRoot.addChildNode(mockChild1)
 
-	mockChild2 := NewEmptyNode()
-	mockChild2.addItems("6", "7")
+	mockChild2 := newEmptyNode[string, string]()
+	addItems(mockChild2, "6", "7")
 	mockRoot.addChildNode(mockChild2)
 
-	expectedRoot := NewEmptyNode()
-	expectedRoot.addItems("4")
-	expectedTree := newTreeWithRoot(expectedRoot, minItems)
+	expectedRoot := newEmptyNode[string, string]()
+	addItems(expectedRoot, "4")
+	expectedTree := newTreeWithRoot(expectedRoot, minItems, strings.Compare)
 
-	expectedChild0 := NewEmptyNode()
-	expectedChild0.addItems("0", "1", "2", "3")
+	expectedChild0 := newEmptyNode[string, string]()
+	addItems(expectedChild0, "0", "1", "2", "3")
 	expectedRoot.addChildNode(expectedChild0)
 
-	expectedChild1 := NewEmptyNode()
-	expectedChild1.addItems("6", "7")
+	expectedChild1 := newEmptyNode[string, string]()
+	addItems(expectedChild1, "6", "7")
 	expectedRoot.addChildNode(expectedChild1)
 
-
 	// Remove an element
 	mockTree.Remove("5")
 	areTreesEqual(t, expectedTree, mockTree)
 }
 
 func Test_BucketRemoveFromInnerNodeAndRotateLeft(t *testing.T) {
-	mockRoot := NewEmptyNode()
-	mockRoot.addItems("8")
-	mockTree := newTreeWithRoot(mockRoot, minItems)
+	mockRoot := newEmptyNode[string, string]()
+	addItems(mockRoot, "8")
+	mockTree := newTreeWithRoot(mockRoot, minItems, strings.Compare)
 
-	mockChild0 := NewEmptyNode()
-	mockChild0.addItems("2", "5")
+	mockChild0 := newEmptyNode[string, string]()
+	addItems(mockChild0, "2", "5")
 	mockRoot.addChildNode(mockChild0)
-	mockChild00 := NewEmptyNode()
-	mockChild00.addItems("0", "1")
+	mockChild00 := newEmptyNode[string, string]()
+	addItems(mockChild00, "0", "1")
 	mockChild0.addChildNode(mockChild00)
-	mockChild01 := NewEmptyNode()
-	mockChild01.addItems("3", "4")
+	mockChild01 := newEmptyNode[string, string]()
+	addItems(mockChild01, "3", "4")
 	mockChild0.addChildNode(mockChild01)
-	mockChild02 := NewEmptyNode()
-	mockChild02.addItems("6", "7")
+	mockChild02 := newEmptyNode[string, string]()
+	addItems(mockChild02, "6", "7")
 	mockChild0.addChildNode(mockChild02)
 
-	mockChild1 := NewEmptyNode()
-	mockChild1.addItems("b", "e", "h")
+	mockChild1 := newEmptyNode[string, string]()
+	addItems(mockChild1, "b", "e", "h")
 	mockRoot.addChildNode(mockChild1)
-	mockChild10 := NewEmptyNode()
-	mockChild10.addItems("9", "a")
+	mockChild10 := newEmptyNode[string, string]()
+	addItems(mockChild10, "9", "a")
 	mockChild1.addChildNode(mockChild10)
-	mockChild11 := NewEmptyNode()
-	mockChild11.addItems("c", "d")
+	mockChild11 := newEmptyNode[string, string]()
+	addItems(mockChild11, "c", "d")
 	mockChild1.addChildNode(mockChild11)
-	mockChild12 := NewEmptyNode()
-	mockChild12.addItems("f", "g")
+	mockChild12 := newEmptyNode[string, string]()
+	addItems(mockChild12, "f", "g")
 	mockChild1.addChildNode(mockChild12)
-	mockChild13 := NewEmptyNode()
-	mockChild13.addItems("i", "j")
+	mockChild13 := newEmptyNode[string, string]()
+	addItems(mockChild13, "i", "j")
 	mockChild1.addChildNode(mockChild13)
 
-	expectedRoot := NewEmptyNode()
-	expectedRoot.addItems("b")
-	expectedTree := newTreeWithRoot(expectedRoot, minItems)
+	expectedRoot := newEmptyNode[string, string]()
+	addItems(expectedRoot, "b")
+	expectedTree := newTreeWithRoot(expectedRoot, minItems, strings.Compare)
 
-	expectedChild0 := NewEmptyNode()
-	expectedChild0.addItems("4", "8")
+	expectedChild0 := newEmptyNode[string, string]()
+	addItems(expectedChild0, "4", "8")
 	expectedRoot.addChildNode(expectedChild0)
-	expectedChild00 := NewEmptyNode()
-	expectedChild00.addItems("0", "1", "2", "3")
+	expectedChild00 := newEmptyNode[string, string]()
+	addItems(expectedChild00, "0", "1", "2", "3")
 	expectedChild0.addChildNode(expectedChild00)
-	expectedChild01 := NewEmptyNode()
-	expectedChild01.addItems("6", "7")
+	expectedChild01 := newEmptyNode[string, string]()
+	addItems(expectedChild01, "6", "7")
 	expectedChild0.addChildNode(expectedChild01)
-	expectedChild02 := NewEmptyNode()
-	expectedChild02.addItems("9", "a")
+	expectedChild02 := newEmptyNode[string, string]()
+	addItems(expectedChild02, "9", "a")
 	expectedChild0.addChildNode(expectedChild02)
 
-	expectedChild1 := NewEmptyNode()
-	expectedChild1.addItems("e", "h")
+	expectedChild1 := newEmptyNode[string, string]()
+	addItems(expectedChild1, "e", "h")
 	expectedRoot.addChildNode(expectedChild1)
-	expectedChild10 := NewEmptyNode()
-	expectedChild10.addItems("c", "d")
+	expectedChild10 := newEmptyNode[string, string]()
+	addItems(expectedChild10, "c", "d")
 	expectedChild1.addChildNode(expectedChild10)
-	expectedChild11 := NewEmptyNode()
-	expectedChild11.addItems("f", "g")
+	expectedChild11 := newEmptyNode[string, string]()
+	addItems(expectedChild11, "f", "g")
 	expectedChild1.addChildNode(expectedChild11)
-	expectedChild12 := NewEmptyNode()
-	expectedChild12.addItems("i", "j")
+	expectedChild12 := newEmptyNode[string, string]()
+	addItems(expectedChild12, "i", "j")
 	expectedChild1.addChildNode(expectedChild12)
 
 	// Remove an element
@@ -442,67 +444,67 @@ func Test_BucketRemoveFromInnerNodeAndRotateLeft(t *testing.T) {
 }
 
 func Test_BucketRemoveFromInnerNodeAndRotateRight(t *testing.T) {
-	mockRoot := NewEmptyNode()
-	mockRoot.addItems("b")
-	mockTree := newTreeWithRoot(mockRoot, minItems)
+	mockRoot := newEmptyNode[string, string]()
+	addItems(mockRoot, "b")
+	mockTree := newTreeWithRoot(mockRoot, minItems, strings.Compare)
 
-	mockChild0 := NewEmptyNode()
-	mockChild0.addItems("2", "5", "8")
+	mockChild0 := newEmptyNode[string, string]()
+	addItems(mockChild0, "2", "5", "8")
 	mockRoot.addChildNode(mockChild0)
-	mockChild00 := NewEmptyNode()
-	mockChild00.addItems("0", "1")
+	mockChild00 := newEmptyNode[string, string]()
+	addItems(mockChild00, "0", "1")
 	mockChild0.addChildNode(mockChild00)
-	mockChild01 := NewEmptyNode()
-	mockChild01.addItems("3", "4")
+	mockChild01 := newEmptyNode[string, string]()
+	addItems(mockChild01, "3", "4")
 	mockChild0.addChildNode(mockChild01)
-	mockChild02 := NewEmptyNode()
-	mockChild02.addItems("6", "7")
+	mockChild02 := newEmptyNode[string, string]()
+	addItems(mockChild02, "6", "7")
 	mockChild0.addChildNode(mockChild02)
-	mockChild03 := NewEmptyNode()
-	mockChild03.addItems("9", "a")
+	mockChild03 := newEmptyNode[string, string]()
+	addItems(mockChild03, "9", "a")
 	mockChild0.addChildNode(mockChild03)
 
-	mockChild1 := NewEmptyNode()
-	mockChild1.addItems("e", "h")
+	mockChild1 := newEmptyNode[string, string]()
+	addItems(mockChild1, "e", "h")
 	mockRoot.addChildNode(mockChild1)
-	mockChild10 := NewEmptyNode()
-	mockChild10.addItems("c", "d")
+	mockChild10 := newEmptyNode[string, string]()
+	addItems(mockChild10, "c", "d")
 	mockChild1.addChildNode(mockChild10)
-	mockChild11 := NewEmptyNode()
-	mockChild11.addItems("f", "g")
+	mockChild11 := newEmptyNode[string, string]()
+	addItems(mockChild11, "f", "g")
 	mockChild1.addChildNode(mockChild11)
-	mockChild12 := NewEmptyNode()
-	mockChild12.addItems("i", "j")
+	mockChild12 := newEmptyNode[string, string]()
+	addItems(mockChild12, "i", "j")
 	mockChild1.addChildNode(mockChild12)
 
-	expectedRoot := NewEmptyNode()
-	expectedRoot.addItems("8")
-	expectedTree := newTreeWithRoot(expectedRoot, minItems)
+	expectedRoot := newEmptyNode[string, string]()
+	addItems(expectedRoot, "8")
+	expectedTree := newTreeWithRoot(expectedRoot, minItems, strings.Compare)
 
-	expectedChild0 := NewEmptyNode()
-	expectedChild0.addItems("2", "5")
+	expectedChild0 := newEmptyNode[string, string]()
+	addItems(expectedChild0, "2", "5")
 	expectedRoot.addChildNode(expectedChild0)
-	expectedChild00 := NewEmptyNode()
-	expectedChild00.addItems("0", "1")
+	expectedChild00 := newEmptyNode[string, string]()
+	addItems(expectedChild00, "0", "1")
 	expectedChild0.addChildNode(expectedChild00)
-	expectedChild01 := NewEmptyNode()
-	expectedChild01.addItems("3", "4")
+	expectedChild01 := newEmptyNode[string, string]()
+	addItems(expectedChild01, "3", "4")
 	expectedChild0.addChildNode(expectedChild01)
-	expectedChild02 := NewEmptyNode()
-	expectedChild02.addItems("6", "7")
+	expectedChild02 := newEmptyNode[string, string]()
+	addItems(expectedChild02, "6", "7")
 	expectedChild0.addChildNode(expectedChild02)
 
-	expectedChild1 := NewEmptyNode()
-	expectedChild1.addItems("b", "h")
+	expectedChild1 := newEmptyNode[string, string]()
+	addItems(expectedChild1, "b", "h")
 	expectedRoot.addChildNode(expectedChild1)
-	expectedChild10 := NewEmptyNode()
-	expectedChild10.addItems("9", "a")
+	expectedChild10 := newEmptyNode[string, string]()
+	addItems(expectedChild10, "9", "a")
 	expectedChild1.addChildNode(expectedChild10)
-	expectedChild11 := NewEmptyNode()
-	expectedChild11.addItems("c", "d", "f", "g")
+	expectedChild11 := newEmptyNode[string, string]()
+	addItems(expectedChild11, "c", "d", "f", "g")
 	expectedChild1.addChildNode(expectedChild11)
-	expectedChild12 := NewEmptyNode()
-	expectedChild12.addItems("i", "j")
+	expectedChild12 := newEmptyNode[string, string]()
+	addItems(expectedChild12, "i", "j")
 	expectedChild1.addChildNode(expectedChild12)
 
 	// Remove an element
@@ -511,54 +513,54 @@ func Test_BucketRemoveFromInnerNodeAndRotateRight(t *testing.T) {
 }
 
 func Test_BucketRemoveFromInnerNodeAndUnion(t *testing.T) {
-	mockRoot := NewEmptyNode()
-	mockRoot.addItems("8")
-	mockTree := newTreeWithRoot(mockRoot, minItems)
+	mockRoot := newEmptyNode[string, string]()
+	addItems(mockRoot, "8")
+	mockTree := newTreeWithRoot(mockRoot, minItems, strings.Compare)
 
-	mockChild0 := NewEmptyNode()
-	mockChild0.addItems("2", "5")
+	mockChild0 := newEmptyNode[string, string]()
+	addItems(mockChild0, "2", "5")
 	mockRoot.addChildNode(mockChild0)
-	mockChild00 := NewEmptyNode()
-	mockChild00.addItems("0", "1")
+	mockChild00 := newEmptyNode[string, string]()
+	addItems(mockChild00, "0", "1")
 	mockChild0.addChildNode(mockChild00)
-	mockChild01 := NewEmptyNode()
-	mockChild01.addItems("3", "4")
+	mockChild01 := newEmptyNode[string, string]()
+	addItems(mockChild01, "3", "4")
 	mockChild0.addChildNode(mockChild01)
-	mockChild02 := NewEmptyNode()
-	mockChild02.addItems("6", "7")
+	mockChild02 := newEmptyNode[string, string]()
+	addItems(mockChild02, "6", "7")
 	mockChild0.addChildNode(mockChild02)
 
-	mockChild1 := NewEmptyNode()
-	mockChild1.addItems("b", "e")
+	mockChild1 := newEmptyNode[string, string]()
+	addItems(mockChild1, "b", "e")
 	mockRoot.addChildNode(mockChild1)
-	mockChild10 := NewEmptyNode()
-	mockChild10.addItems("9", "a")
+	mockChild10 := newEmptyNode[string, string]()
+	addItems(mockChild10, "9", "a")
 	mockChild1.addChildNode(mockChild10)
-	mockChild11 := NewEmptyNode()
-	mockChild11.addItems("c", "d")
+	mockChild11 := newEmptyNode[string, string]()
+	addItems(mockChild11, "c", "d")
 	mockChild1.addChildNode(mockChild11)
-	mockChild12 := NewEmptyNode()
-	mockChild12.addItems("f", "g")
+	mockChild12 := newEmptyNode[string, string]()
+	addItems(mockChild12, "f", "g")
 	mockChild1.addChildNode(mockChild12)
 
-	expectedRoot := NewEmptyNode()
-	expectedRoot.addItems("5", "8", "b", "e")
-	expectedTree := newTreeWithRoot(expectedRoot, minItems)
+	expectedRoot := newEmptyNode[string, string]()
+	addItems(expectedRoot, "5", "8", "b", "e")
+	expectedTree := newTreeWithRoot(expectedRoot, minItems, strings.Compare)
 
-	expectedChild0 := NewEmptyNode()
-	expectedChild0.addItems("0", "1", "3", "4")
+	expectedChild0 := newEmptyNode[string, string]()
+	addItems(expectedChild0, "0", "1", "3", "4")
 	expectedRoot.addChildNode(expectedChild0)
-	expectedChild1 := NewEmptyNode()
-	expectedChild1.addItems("6", "7")
+	expectedChild1 := newEmptyNode[string, string]()
+	addItems(expectedChild1, "6", "7")
 	expectedRoot.addChildNode(expectedChild1)
-	expectedChild2 := NewEmptyNode()
-	expectedChild2.addItems("9", "a")
+	expectedChild2 := newEmptyNode[string, string]()
+	addItems(expectedChild2, "9", "a")
 	expectedRoot.addChildNode(expectedChild2)
-	expectedChild3 := NewEmptyNode()
-	expectedChild3.addItems("c", "d")
+	expectedChild3 := newEmptyNode[string, string]()
+	addItems(expectedChild3, "c", "d")
 	expectedRoot.addChildNode(expectedChild3)
-	expectedChild4 := NewEmptyNode()
-	expectedChild4.addItems("f", "g")
+	expectedChild4 := newEmptyNode[string, string]()
+	addItems(expectedChild4, "f", "g")
 	expectedRoot.addChildNode(expectedChild4)
 
 	// Remove an element
@@ -567,64 +569,64 @@ func Test_BucketRemoveFromInnerNodeAndUnion(t *testing.T) {
 }
 
 func Test_BucketRemoveFromLeafAndRotateLeft(t *testing.T) {
-	mockRoot := NewEmptyNode()
-	mockRoot.addItems("9")
-	mockTree := newTreeWithRoot(mockRoot, minItems)
+	mockRoot := newEmptyNode[string, string]()
+	addItems(mockRoot, "9")
+	mockTree := newTreeWithRoot(mockRoot, minItems, strings.Compare)
 
-	mockChild0 := NewEmptyNode()
-	mockChild0.addItems("2", "6")
+	mockChild0 := newEmptyNode[string, string]()
+	addItems(mockChild0, "2", "6")
 	mockRoot.addChildNode(mockChild0)
-	mockChild00 := NewEmptyNode()
-	mockChild00.addItems("0", "1")
+	mockChild00 := newEmptyNode[string, string]()
+	addItems(mockChild00, "0", "1")
 	mockChild0.addChildNode(mockChild00)
-	mockChild01 := NewEmptyNode()
-	mockChild01.addItems("3", "4", "5")
+	mockChild01 := newEmptyNode[string, string]()
+	addItems(mockChild01, "3", "4", "5")
 	mockChild0.addChildNode(mockChild01)
-	mockChild02 := NewEmptyNode()
-	mockChild02.addItems("7", "8")
+	mockChild02 := newEmptyNode[string, string]()
+	addItems(mockChild02, "7", "8")
 	mockChild0.addChildNode(mockChild02)
 
-	mockChild1 := NewEmptyNode()
-	mockChild1.addItems("c", "f")
+	mockChild1 := newEmptyNode[string, string]()
+	addItems(mockChild1, "c", "f")
 	mockRoot.addChildNode(mockChild1)
-	mockChild10 := NewEmptyNode()
-	mockChild10.addItems("a", "b")
+	mockChild10 := newEmptyNode[string, string]()
+	addItems(mockChild10, "a", "b")
 	mockChild1.addChildNode(mockChild10)
-	mockChild11 := NewEmptyNode()
-	mockChild11.addItems("d", "e")
+	mockChild11 := newEmptyNode[string, string]()
+	addItems(mockChild11, "d", "e")
 	mockChild1.addChildNode(mockChild11)
-	mockChild12 := NewEmptyNode()
-	mockChild12.addItems("g", "h")
+	mockChild12 := newEmptyNode[string, string]()
+	addItems(mockChild12, "g", "h")
 	mockChild1.addChildNode(mockChild12)
 
-	expectedRoot := NewEmptyNode()
-	expectedRoot.addItems("9")
-	expectedTree := newTreeWithRoot(expectedRoot, minItems)
+	expectedRoot := newEmptyNode[string, string]()
+	addItems(expectedRoot, "9")
+	expectedTree := newTreeWithRoot(expectedRoot, minItems, strings.Compare)
 
-	expectedChild0 := NewEmptyNode()
-	expectedChild0.addItems("3", "6")
+	expectedChild0 := newEmptyNode[string, string]()
+	addItems(expectedChild0, "3", "6")
 	expectedRoot.addChildNode(expectedChild0)
-	expectedChild00 := NewEmptyNode()
-	expectedChild00.addItems("0", "2")
+	expectedChild00 := newEmptyNode[string, string]()
+	addItems(expectedChild00, "0", "2")
 	expectedChild0.addChildNode(expectedChild00)
-	expectedChild01 := NewEmptyNode()
-	expectedChild01.addItems("4", "5")
+	expectedChild01 := newEmptyNode[string, string]()
+	addItems(expectedChild01, "4", "5")
 	expectedChild0.addChildNode(expectedChild01)
-	expectedChild02 := NewEmptyNode()
-	expectedChild02.addItems("7", "8")
+	expectedChild02 := newEmptyNode[string, string]()
+	addItems(expectedChild02, "7", "8")
 	expectedChild0.addChildNode(expectedChild02)
 
-	expectedChild1 := NewEmptyNode()
-	expectedChild1.addItems("c", "f")
+	expectedChild1 := newEmptyNode[string, string]()
+	addItems(expectedChild1, "c", "f")
 	expectedRoot.addChildNode(expectedChild1)
-	expectedChild10 := NewEmptyNode()
-	expectedChild10.addItems("a", "b")
+	expectedChild10 := newEmptyNode[string, string]()
+	addItems(expectedChild10, "a", "b")
 	expectedChild1.addChildNode(expectedChild10)
-	expectedChild11 := NewEmptyNode()
-	expectedChild11.addItems("d", "e")
+	expectedChild11 := newEmptyNode[string, string]()
+	addItems(expectedChild11, "d", "e")
 	expectedChild1.addChildNode(expectedChild11)
-	expectedChild12 := NewEmptyNode()
-	expectedChild12.addItems("g", "h")
+	expectedChild12 := newEmptyNode[string, string]()
+	addItems(expectedChild12, "g", "h")
 	expectedChild1.addChildNode(expectedChild12)
 
 	// Remove an element
@@ -633,64 +635,64 @@ func Test_BucketRemoveFromLeafAndRotateLeft(t *testing.T) {
 }
 
 func Test_BucketRemoveFromLeafAndRotateRight(t *testing.T) {
-	mockRoot := NewEmptyNode()
-	mockRoot.addItems("9")
-	mockTree := newTreeWithRoot(mockRoot, minItems)
+	mockRoot := newEmptyNode[string, string]()
+	addItems(mockRoot, "9")
+	mockTree := newTreeWithRoot(mockRoot, minItems, strings.Compare)
 
-	mockChild0 := NewEmptyNode()
-	mockChild0.addItems("2", "6")
+	mockChild0 := newEmptyNode[string, string]()
+	addItems(mockChild0, "2", "6")
 	mockRoot.addChildNode(mockChild0)
-	mockChild00 := NewEmptyNode()
-	mockChild00.addItems("0", "1")
+	mockChild00 := newEmptyNode[string, string]()
+	addItems(mockChild00, "0", "1")
 	mockChild0.addChildNode(mockChild00)
-	mockChild01 := NewEmptyNode()
-	mockChild01.addItems("3", "4", "5")
+	mockChild01 := newEmptyNode[string, string]()
+	addItems(mockChild01, "3", "4", "5")
 	mockChild0.addChildNode(mockChild01)
-	mockChild02 := NewEmptyNode()
-	mockChild02.addItems("7", "8")
+	mockChild02 := newEmptyNode[string, string]()
+	addItems(mockChild02, "7", "8")
 	mockChild0.addChildNode(mockChild02)
 
-	mockChild1 := NewEmptyNode()
-	mockChild1.addItems("c", "f")
+	mockChild1 := newEmptyNode[string, string]()
+	addItems(mockChild1, "c", "f")
 	mockRoot.addChildNode(mockChild1)
-	mockChild10 := NewEmptyNode()
-	mockChild10.addItems("a", "b")
+	mockChild10 := newEmptyNode[string, string]()
+	addItems(mockChild10, "a", "b")
 	mockChild1.addChildNode(mockChild10)
-	mockChild11 := NewEmptyNode()
-	mockChild11.addItems("d", "e")
+	mockChild11 := newEmptyNode[string, string]()
+	addItems(mockChild11, "d", "e")
 	mockChild1.addChildNode(mockChild11)
-	mockChild12 := NewEmptyNode()
-	mockChild12.addItems("g", "h")
+	mockChild12 := newEmptyNode[string, string]()
+	addItems(mockChild12, "g", "h")
 	mockChild1.addChildNode(mockChild12)
 
-	expectedRoot := NewEmptyNode()
-	expectedRoot.addItems("9")
-	expectedTree := newTreeWithRoot(expectedRoot, minItems)
+	expectedRoot := newEmptyNode[string, string]()
+	addItems(expectedRoot, "9")
+	expectedTree := newTreeWithRoot(expectedRoot, minItems, strings.Compare)
 
-	expectedChild0 := NewEmptyNode()
-	expectedChild0.addItems("2", "5")
+	expectedChild0 := newEmptyNode[string, string]()
+	addItems(expectedChild0, "2", "5")
 	expectedRoot.addChildNode(expectedChild0)
-	expectedChild00 := NewEmptyNode()
-	expectedChild00.addItems("0", "1")
+	expectedChild00 := newEmptyNode[string, string]()
+	addItems(expectedChild00, "0", "1")
 	expectedChild0.addChildNode(expectedChild00)
-	expectedChild01 := NewEmptyNode()
-	expectedChild01.addItems("3", "4")
+	expectedChild01 := newEmptyNode[string, string]()
+	addItems(expectedChild01, "3", "4")
 	expectedChild0.addChildNode(expectedChild01)
-	expectedChild02 := NewEmptyNode()
-	expectedChild02.addItems("6", "7")
+	expectedChild02 := newEmptyNode[string, string]()
+	addItems(expectedChild02, "6", "7")
 	expectedChild0.addChildNode(expectedChild02)
 
-	expectedChild1 := NewEmptyNode()
-	expectedChild1.addItems("c", "f")
+	expectedChild1 := newEmptyNode[string, string]()
+	addItems(expectedChild1, "c", "f")
 	expectedRoot.addChildNode(expectedChild1)
-	expectedChild10 := NewEmptyNode()
-	expectedChild10.addItems("a", "b")
+	expectedChild10 := newEmptyNode[string, string]()
+	addItems(expectedChild10, "a", "b")
 	expectedChild1.addChildNode(expectedChild10)
-	expectedChild11 := NewEmptyNode()
-	expectedChild11.addItems("d", "e")
+	expectedChild11 := newEmptyNode[string, string]()
+	addItems(expectedChild11, "d", "e")
 	expectedChild1.addChildNode(expectedChild11)
-	expectedChild12 := NewEmptyNode()
-	expectedChild12.addItems("g", "h")
+	expectedChild12 := newEmptyNode[string, string]()
+	addItems(expectedChild12, "g", "h")
 	expectedChild1.addChildNode(expectedChild12)
 
 	// Remove an element
@@ -699,54 +701,54 @@ func Test_BucketRemoveFromLeafAndRotateRight(t *testing.T) {
 }
 
 func Test_BucketRemoveFromLeafAndUnion(t *testing.T) {
-	mockRoot := NewEmptyNode()
-	mockRoot.addItems("8")
-	mockTree := newTreeWithRoot(mockRoot, minItems)
+	mockRoot := newEmptyNode[string, string]()
+	addItems(mockRoot, "8")
+	mockTree := newTreeWithRoot(mockRoot, minItems, strings.Compare)
 
-	mockChild0 := NewEmptyNode()
-	mockChild0.addItems("2", "5")
+	mockChild0 := newEmptyNode[string, string]()
+	addItems(mockChild0, "2", "5")
 	mockRoot.addChildNode(mockChild0)
-	mockChild00 := NewEmptyNode()
-	mockChild00.addItems("0", "1")
+	mockChild00 := newEmptyNode[string, string]()
+	addItems(mockChild00, "0", "1")
 	mockChild0.addChildNode(mockChild00)
-	mockChild01 := NewEmptyNode()
-	mockChild01.addItems("3", "4")
+	mockChild01 := newEmptyNode[string, string]()
+	addItems(mockChild01, "3", "4")
 	mockChild0.addChildNode(mockChild01)
-	mockChild02 := NewEmptyNode()
-	mockChild02.addItems("6", "7")
+	mockChild02 := newEmptyNode[string, string]()
+	addItems(mockChild02, "6", "7")
 	mockChild0.addChildNode(mockChild02)
 
-	mockChild1 := NewEmptyNode()
-	mockChild1.addItems("b", "e")
+	mockChild1 := newEmptyNode[string, string]()
+	addItems(mockChild1, "b", "e")
 	mockRoot.addChildNode(mockChild1)
-	mockChild10 := NewEmptyNode()
-	mockChild10.addItems("9", "a")
+	mockChild10 := newEmptyNode[string, string]()
+	addItems(mockChild10, "9", "a")
 	mockChild1.addChildNode(mockChild10)
-	mockChild11 := NewEmptyNode()
-	mockChild11.addItems("c", "d")
+	mockChild11 := newEmptyNode[string, string]()
+	addItems(mockChild11, "c", "d")
 	mockChild1.addChildNode(mockChild11)
-	mockChild12 := NewEmptyNode()
-	mockChild12.addItems("f", "g")
+	mockChild12 := newEmptyNode[string, string]()
+	addItems(mockChild12, "f", "g")
 	mockChild1.addChildNode(mockChild12)
 
-	expectedRoot := NewEmptyNode()
-	expectedRoot.addItems("5", "8", "b", "e")
-	expectedTree := newTreeWithRoot(expectedRoot, minItems)
+	expectedRoot := newEmptyNode[string, string]()
+	addItems(expectedRoot, "5", "8", "b", "e")
+	expectedTree := newTreeWithRoot(expectedRoot, minItems, strings.Compare)
 
-	expectedChild00 := NewEmptyNode()
-	expectedChild00.addItems("1", "2", "3", "4")
+	expectedChild00 := newEmptyNode[string, string]()
+	addItems(expectedChild00, "1", "2", "3", "4")
 	expectedRoot.addChildNode(expectedChild00)
-	expectedChild01 := NewEmptyNode()
-	expectedChild01.addItems("6", "7")
+	expectedChild01 := newEmptyNode[string, string]()
+	addItems(expectedChild01, "6", "7")
 	expectedRoot.addChildNode(expectedChild01)
-	expectedChild02 := NewEmptyNode()
-	expectedChild02.addItems("9", "a")
+	expectedChild02 := newEmptyNode[string, string]()
+	addItems(expectedChild02, "9", "a")
 	expectedRoot.addChildNode(expectedChild02)
-	expectedChild03 := NewEmptyNode()
-	expectedChild03.addItems("c", "d")
+	expectedChild03 := newEmptyNode[string, string]()
+	addItems(expectedChild03, "c", "d")
 	expectedRoot.addChildNode(expectedChild03)
-	expectedChild04 := NewEmptyNode()
-	expectedChild04.addItems("f", "g")
+	expectedChild04 := newEmptyNode[string, string]()
+	addItems(expectedChild04, "f", "g")
 	expectedRoot.addChildNode(expectedChild04)
 
 	// Remove an element
@@ -755,34 +757,34 @@ func Test_BucketRemoveFromLeafAndUnion(t *testing.T) {
 }
 
 func Test_BucketFindNode(t *testing.T) {
-	mockRoot := NewEmptyNode()
-	mockRoot.addItems("8")
-	mockTree := newTreeWithRoot(mockRoot, minItems)
+	mockRoot := newEmptyNode[string, string]()
+	addItems(mockRoot, "8")
+	mockTree := newTreeWithRoot(mockRoot, minItems, strings.Compare)
 
-	mockChild0 := NewEmptyNode()
-	mockChild0.addItems("2", "5")
+	mockChild0 := newEmptyNode[string, string]()
+	addItems(mockChild0, "2", "5")
 	mockRoot.addChildNode(mockChild0)
-	mockChild00 := NewEmptyNode()
-	mockChild00.addItems("0", "1")
+	mockChild00 := newEmptyNode[string, string]()
+	addItems(mockChild00, "0", "1")
 	mockChild0.addChildNode(mockChild00)
-	mockChild01 := NewEmptyNode()
-	mockChild01.addItems("3", "4")
+	mockChild01 := newEmptyNode[string, string]()
+	addItems(mockChild01, "3", "4")
 	mockChild0.addChildNode(mockChild01)
-	mockChild02 := NewEmptyNode()
-	mockChild02.addItems("6", "7")
+	mockChild02 := newEmptyNode[string, string]()
+	addItems(mockChild02, "6", "7")
 	mockChild0.addChildNode(mockChild02)
 
-	mockChild1 := NewEmptyNode()
-	mockChild1.addItems("b", "e")
+	mockChild1 := newEmptyNode[string, string]()
+	addItems(mockChild1, "b", "e")
 	mockRoot.addChildNode(mockChild1)
-	mockChild10 := NewEmptyNode()
-	mockChild10.addItems("9", "a")
+	mockChild10 := newEmptyNode[string, string]()
+	addItems(mockChild10, "9", "a")
 	mockChild1.addChildNode(mockChild10)
-	mockChild11 := NewEmptyNode()
-	mockChild11.addItems("c", "d")
+	mockChild11 := newEmptyNode[string, string]()
+	addItems(mockChild11, "c", "d")
 	mockChild1.addChildNode(mockChild11)
-	mockChild12 := NewEmptyNode()
-	mockChild12.addItems("f", "g")
+	mockChild12 := newEmptyNode[string, string]()
+	addItems(mockChild12, "f", "g")
 	mockChild1.addChildNode(mockChild12)
 
 	// Item found
@@ -797,36 +799,38 @@ func Test_BucketFindNode(t *testing.T) {
 }
 
 func Test_BucketUpdateNode(t *testing.T) {
-	mockRoot := NewEmptyNode()
-	mockRoot.addItems("8")
-	mockTree := newTreeWithRoot(mockRoot, minItems)
+	mockRoot := newEmptyNode[string, string]()
+	addItems(mockRoot, "8")
+	mockTree := newTreeWithRoot(mockRoot, minItems, strings.Compare)
 
-	mockChild0 := NewEmptyNode()
-	mockChild0.addItems("2", "5")
+	mockChild0 := newEmptyNode[string, string]()
+	addItems(mockChild0, "2", "5")
 	mockRoot.addChildNode(mockChild0)
-	mockChild00 := NewEmptyNode()
-	mockChild00.addItems("0", "1")
+	mockChild00 := newEmptyNode[string, string]()
+	addItems(mockChild00, "0", "1")
 	mockChild0.addChildNode(mockChild00)
-	mockChild01 := NewEmptyNode()
-	mockChild01.addItems("3", "4")
+	mockChild01 := newEmptyNode[string, string]()
+	addItems(mockChild01, "3", "4")
 	mockChild0.addChildNode(mockChild01)
-	mockChild02 := NewEmptyNode()
-	mockChild02.addItems("6", "7")
+	mockChild02 := newEmptyNode[string, string]()
+	addItems(mockChild02, "6", "7")
 	mockChild0.addChildNode(mockChild02)
 
-	mockChild1 := NewEmptyNode()
-	mockChild1.addItems("b", "e")
+	mockChild1 := newEmptyNode[string, string]()
+	addItems(mockChild1, "b", "e")
 	mockRoot.addChildNode(mockChild1)
-	mockChild10 := NewEmptyNode()
-	mockChild10.addItems("9", "a")
+	mockChild10 := newEmptyNode[string, string]()
+	addItems(mockChild10, "9", "a")
 	mockChild1.addChildNode(mockChild10)
-	mockChild11 := NewEmptyNode()
-	mockChild11.addItems("c", "d")
+	mockChild11 := newEmptyNode[string, string]()
+	addItems(mockChild11, "c", "d")
 	mockChild1.addChildNode(mockChild11)
-	mockChild12 := NewEmptyNode()
-	mockChild12.addItems("f", "g")
+	mockChild12 := newEmptyNode[string, string]()
+	addItems(mockChild12, "f", "g")
 	mockChild1.addChildNode(mockChild12)
 
+	recountTree(mockTree)
+
 	// Item found
 	expectedItem := newItem("c", "c")
 	item := mockTree.Find("c")
@@ -834,7 +838,316 @@ func Test_BucketUpdateNode(t *testing.T) {
 
 	// Item updated successfully
 	newvalue := "f"
-	mockTree.Put("c",newvalue)
+	oldValue, replaced := mockTree.Put("c", newvalue)
+	assert.True(t, replaced)
+	assert.Equal(t, "c", oldValue)
 	item = mockTree.Find("c")
 	assert.Equal(t, newvalue, item.value)
+	assert.Equal(t, []string{"c", "d"}, nodeKeys(mockChild11))
+
+	// Items in internal nodes are updated in place as well instead of being added a second time
+	oldValue, replaced = mockTree.Put("8", newvalue)
+	assert.True(t, replaced)
+	assert.Equal(t, "8", oldValue)
+	assert.Equal(t, []string{"8"}, nodeKeys(mockRoot))
+
+	oldValue, replaced = mockTree.Put("b", newvalue)
+	assert.True(t, replaced)
+	assert.Equal(t, "b", oldValue)
+	assert.Equal(t, []string{"b", "e"}, nodeKeys(mockChild1))
+	value, _ := mockTree.Get("b")
+	assert.Equal(t, newvalue, value)
+	checkTreeInvariants(t, mockTree)
+}
+
+func nodeKeys[K any, V any](n *Node[K, V]) []K {
+	keys := make([]K, 0, len(n.items))
+	for _, item := range n.items {
+		keys = append(keys, item.key)
+	}
+	return keys
+}
+
+func Test_BucketPutNewKey(t *testing.T) {
+	mockTree := createTestMockTree()
+
+	oldValue, replaced := mockTree.Put("a", "a")
+	assert.False(t, replaced)
+	assert.Equal(t, "", oldValue)
+	value, found := mockTree.Get("a")
+	assert.True(t, found)
+	assert.Equal(t, "a", value)
+}
+
+func Test_BucketInsert(t *testing.T) {
+	mockTree := createTestMockTree()
+
+	err := mockTree.Insert("a", "a")
+	require.NoError(t, err)
+
+	err = mockTree.Insert("5", "changed")
+	assert.ErrorIs(t, err, ErrKeyExists)
+	value, _ := mockTree.Get("5")
+	assert.Equal(t, "5", value)
+
+	err = mockTree.Insert("a", "changed")
+	assert.ErrorIs(t, err, ErrKeyExists)
+	value, _ = mockTree.Get("a")
+	assert.Equal(t, "a", value)
+}
+
+func Test_TreeOrderedKeys(t *testing.T) {
+	tree := NewTree[int, int](minItems)
+	for i := 0; i < 100; i++ {
+		tree.Put(i, i*10)
+	}
+
+	// Integer keys are compared numerically, so "10" isn't placed before "9" like it would with strings.
+	for i := 0; i < 100; i++ {
+		item := tree.Find(i)
+		require.NotNil(t, item)
+		assert.Equal(t, i*10, item.value)
+	}
+	assert.Nil(t, tree.Find(100))
+}
+
+func Test_TreeCustomComparator(t *testing.T) {
+	type point struct{ x, y int }
+	compare := func(a, b point) int {
+		if a.x != b.x {
+			return a.x - b.x
+		}
+		return a.y - b.y
+	}
+	tree := NewTreeFunc[point, string](minItems, compare)
+	for x := 0; x < 5; x++ {
+		for y := 0; y < 5; y++ {
+			tree.Put(point{x, y}, strconv.Itoa(x)+","+strconv.Itoa(y))
+		}
+	}
+
+	item := tree.Find(point{3, 1})
+	require.NotNil(t, item)
+	assert.Equal(t, "3,1", item.value)
+	assert.Nil(t, tree.Find(point{5, 0}))
+}
+
+func Test_BucketGet(t *testing.T) {
+	mockTree := createTestMockTree()
+
+	value, found := mockTree.Get("4")
+	assert.True(t, found)
+	assert.Equal(t, "4", value)
+
+	value, found = mockTree.Get("a")
+	assert.False(t, found)
+	assert.Equal(t, "", value)
+}
+
+func Test_BucketFindReturnsCopy(t *testing.T) {
+	mockTree := createTestMockTree()
+
+	item := mockTree.Find("4")
+	require.NotNil(t, item)
+	assert.Equal(t, "4", item.Key())
+	assert.Equal(t, "4", item.Value())
+
+	// Changing the returned item must not change the tree
+	item.value = "changed"
+	value, _ := mockTree.Get("4")
+	assert.Equal(t, "4", value)
+}
+
+// checkTreeInvariants verifies that every node except the root holds between minItems and maxItems items, that the
+// keys are in ascending order and that all the leaves are at the same depth.
+func checkTreeInvariants[K any, V any](t *testing.T, tree *Tree[K, V]) {
+	t.Helper()
+	leafDepth := -1
+	nodeCount := 0
+	var prev *K
+	var walk func(n *Node[K, V], depth int)
+	walk = func(n *Node[K, V], depth int) {
+		if n != tree.root {
+			require.GreaterOrEqual(t, len(n.items), tree.minItems)
+		}
+		require.LessOrEqual(t, len(n.items), tree.maxItems)
+		nodeCount++
+		expectedCount := len(n.items)
+		for _, child := range n.childNodes {
+			expectedCount += child.count
+		}
+		require.Equal(t, expectedCount, n.count)
+		if n.isLeaf() {
+			if leafDepth == -1 {
+				leafDepth = depth
+			}
+			require.Equal(t, leafDepth, depth)
+		} else {
+			require.Equal(t, len(n.items)+1, len(n.childNodes))
+		}
+		for i, item := range n.items {
+			if !n.isLeaf() {
+				walk(n.childNodes[i], depth+1)
+			}
+			if prev != nil {
+				require.Less(t, tree.compare(*prev, item.key), 0)
+			}
+			key := item.key
+			prev = &key
+		}
+		if !n.isLeaf() {
+			walk(n.childNodes[len(n.childNodes)-1], depth+1)
+		}
+	}
+	walk(tree.root, 0)
+	require.Equal(t, nodeCount, tree.NodeCount())
+	require.Equal(t, leafDepth+1, tree.Height())
+}
+
+// recountTree sets the counts of a mock tree whose nodes were created before their children were added.
+func recountTree[K any, V any](tree *Tree[K, V]) {
+	var recount func(n *Node[K, V])
+	tree.nodeCount = 0
+	recount = func(n *Node[K, V]) {
+		for _, child := range n.childNodes {
+			recount(child)
+		}
+		n.updateCount()
+		tree.nodeCount++
+	}
+	recount(tree.root)
+}
+
+func Test_BucketRemoveMissingKey(t *testing.T) {
+	mockTree := createTestMockTree()
+
+	_, err := mockTree.Remove("a")
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+	areTreesEqual(t, createTestMockTree(), mockTree)
+
+	value, err := mockTree.Remove("4")
+	require.NoError(t, err)
+	assert.Equal(t, "4", value)
+	_, err = mockTree.Remove("4")
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+}
+
+func Test_BucketDelete(t *testing.T) {
+	mockTree := createTestMockTree()
+
+	assert.True(t, mockTree.Delete("4"))
+	assert.False(t, mockTree.Delete("4"))
+	assert.False(t, mockTree.Delete("a"))
+
+	_, found := mockTree.Get("4")
+	assert.False(t, found)
+}
+
+func Test_TreeRandomPutAndRemove(t *testing.T) {
+	const numOfElements = 2000
+	r := rand.New(rand.NewSource(1))
+	tree := NewTree[int, int](minItems)
+	expected := map[int]int{}
+
+	for _, key := range r.Perm(numOfElements) {
+		tree.Put(key, key)
+		expected[key] = key
+	}
+	checkTreeInvariants(t, tree)
+
+	for i, key := range r.Perm(numOfElements) {
+		value, err := tree.Remove(key)
+		require.NoError(t, err)
+		require.Equal(t, key, value)
+		delete(expected, key)
+
+		if i%100 == 0 {
+			checkTreeInvariants(t, tree)
+			for k, v := range expected {
+				value, found := tree.Get(k)
+				require.True(t, found)
+				require.Equal(t, v, value)
+			}
+		}
+	}
+	checkTreeInvariants(t, tree)
+	assert.Empty(t, tree.root.items)
+}
+
+func Test_TreeMinMax(t *testing.T) {
+	tree := NewTree[int, string](minItems)
+	_, _, found := tree.Min()
+	assert.False(t, found)
+	_, _, found = tree.Max()
+	assert.False(t, found)
+
+	for _, key := range rand.New(rand.NewSource(1)).Perm(100) {
+		tree.Put(key, strconv.Itoa(key))
+	}
+
+	key, value, found := tree.Min()
+	assert.True(t, found)
+	assert.Equal(t, 0, key)
+	assert.Equal(t, "0", value)
+
+	key, value, found = tree.Max()
+	assert.True(t, found)
+	assert.Equal(t, 99, key)
+	assert.Equal(t, "99", value)
+}
+
+func Test_TreeDeleteMinAndMax(t *testing.T) {
+	tree := NewTree[int, string](minItems)
+	for _, key := range rand.New(rand.NewSource(1)).Perm(100) {
+		tree.Put(key, strconv.Itoa(key))
+	}
+
+	// Pop from both ends until the tree is empty
+	for i := 0; i < 50; i++ {
+		key, value, found := tree.DeleteMin()
+		require.True(t, found)
+		require.Equal(t, i, key)
+		require.Equal(t, strconv.Itoa(i), value)
+
+		key, _, found = tree.DeleteMax()
+		require.True(t, found)
+		require.Equal(t, 99-i, key)
+		checkTreeInvariants(t, tree)
+	}
+
+	_, _, found := tree.DeleteMin()
+	assert.False(t, found)
+	_, _, found = tree.DeleteMax()
+	assert.False(t, found)
+}
+
+func Test_TreeSize(t *testing.T) {
+	tree := NewTree[int, int](minItems)
+	assert.Equal(t, 0, tree.Len())
+	assert.Equal(t, 1, tree.Height())
+	assert.Equal(t, 1, tree.NodeCount())
+
+	r := rand.New(rand.NewSource(1))
+	for i, key := range r.Perm(1000) {
+		tree.Put(key, key)
+		require.Equal(t, i+1, tree.Len())
+	}
+	// Replacing a value doesn't change the size
+	tree.Put(5, 5)
+	assert.Equal(t, 1000, tree.Len())
+	checkTreeInvariants(t, tree)
+	assert.Greater(t, tree.Height(), 1)
+	assert.Greater(t, tree.NodeCount(), 1000/(2*minItems))
+
+	for i, key := range r.Perm(1000) {
+		tree.Delete(key)
+		require.Equal(t, 1000-i-1, tree.Len())
+		if i%50 == 0 {
+			checkTreeInvariants(t, tree)
+		}
+	}
+	tree.Delete(5)
+	assert.Equal(t, 0, tree.Len())
+	assert.Equal(t, 1, tree.Height())
+	assert.Equal(t, 1, tree.NodeCount())
 }
@@ -0,0 +1,115 @@
+package btree
+
+import (
+	"cmp"
+	"errors"
+	"iter"
+)
+
+// ErrUnsorted is returned by BuildFromSorted when the keys aren't strictly ascending, either because they're out of
+// order or because a key repeats.
+var ErrUnsorted = errors.New("btree: keys are not in strictly ascending order")
+
+// ErrInvalidFillFactor is returned by BuildFromSorted when the fill factor isn't in (0, 1].
+var ErrInvalidFillFactor = errors.New("btree: fill factor must be bigger than 0 and at most 1")
+
+// BuildFromSorted creates a tree like NewTree with the items of seq, whose keys must be strictly ascending. The tree is
+// built bottom-up in O(n): the items are packed into leaves, the items between the leaves are packed into the level
+// above, and so on until a single root is left. This avoids the splits of inserting the items one by one, which leave
+// the nodes about half full.
+//
+// fillFactor is the fraction of maxItems each node is packed with. 1 packs the nodes completely, which is the most
+// compact, but the first inserts into a full node split it. Nodes are never packed with less than minItems. If seq
+// isn't sorted, ErrUnsorted is returned.
+func BuildFromSorted[K cmp.Ordered, V any](minItems int, seq iter.Seq2[K, V], fillFactor float64) (*Tree[K, V], error) {
+	return BuildFromSortedFunc(minItems, cmp.Compare[K], seq, fillFactor)
+}
+
+// BuildFromSortedFunc creates a tree like BuildFromSorted, but orders the keys with the given comparator like
+// NewTreeFunc.
+func BuildFromSortedFunc[K any, V any](
+	minItems int, compare func(a, b K) int, seq iter.Seq2[K, V], fillFactor float64,
+) (*Tree[K, V], error) {
+	b := newTreeWithRoot(newEmptyNode[K, V](), minItems, compare)
+	if err := b.build(seq, fillFactor); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// build builds the tree from the sorted items of seq. The tree must be empty.
+func (b *Tree[K, V]) build(seq iter.Seq2[K, V], fillFactor float64) error {
+	if fillFactor <= 0 || fillFactor > 1 {
+		return ErrInvalidFillFactor
+	}
+	itemsInNode := int(fillFactor * float64(b.maxItems))
+	itemsInNode = max(itemsInNode, b.minItems)
+
+	items := []*Item[K, V]{}
+	for key, value := range seq {
+		if len(items) > 0 && b.compare(items[len(items)-1].key, key) >= 0 {
+			return ErrUnsorted
+		}
+		items = append(items, newItem(key, value))
+	}
+
+	nodeCount := 0
+	var nodes []*Node[K, V]
+	for {
+		// The items that separate the nodes of this level are the items of the level above.
+		nodes, items = b.packLevel(items, nodes, itemsInNode)
+		nodeCount += len(nodes)
+		if len(nodes) == 1 {
+			break
+		}
+	}
+	b.root = nodes[0]
+	b.nodeCount = nodeCount
+	return nil
+}
+
+// packLevel packs the items into the nodes of a single level. children are the nodes of the level below, one more than
+// the items, or nil when packing the leaves. It returns the nodes and the items that separate them, one less than the
+// nodes, which go to the level above.
+//
+// Every node but the last one takes itemsInNode items followed by a separating item, so the number of nodes is
+// (len(items)+1)/(itemsInNode+1) rounded up. The items are then spread evenly across the nodes, which keeps the last
+// node from being left with too few items. If spreading them evenly would still leave the nodes with too few items or
+// too many, then the number of nodes is adjusted.
+//
+//	items: 1,2,3,4,5,6,7,8,9   itemsInNode: 3     ------>     nodes: 1,2,3  5,6  8,9   separators: 4,7
+func (b *Tree[K, V]) packLevel(
+	items []*Item[K, V], children []*Node[K, V], itemsInNode int,
+) ([]*Node[K, V], []*Item[K, V]) {
+	numOfNodes := ceilDiv(len(items)+1, itemsInNode+1)
+	numOfNodes = min(numOfNodes, (len(items)+1)/(b.minItems+1))
+	numOfNodes = max(numOfNodes, ceilDiv(len(items)+1, b.maxItems+1), 1)
+
+	itemsInNodes := len(items) - (numOfNodes - 1)
+	nodes := make([]*Node[K, V], 0, numOfNodes)
+	separators := make([]*Item[K, V], 0, numOfNodes-1)
+	for i := 0; i < numOfNodes; i++ {
+		size := itemsInNodes / numOfNodes
+		if i < itemsInNodes%numOfNodes {
+			size++
+		}
+
+		nodeChildren := []*Node[K, V]{}
+		if children != nil {
+			nodeChildren = append(nodeChildren, children[:size+1]...)
+			children = children[size+1:]
+		}
+		nodes = append(nodes, newNode(b.treeConfig, b.owner, append([]*Item[K, V]{}, items[:size]...), nodeChildren))
+		items = items[size:]
+
+		if i < numOfNodes-1 {
+			separators = append(separators, items[0])
+			items = items[1:]
+		}
+	}
+	return nodes, separators
+}
+
+func ceilDiv(a, b int) int {
+	return (a + b - 1) / b
+}
@@ -0,0 +1,124 @@
+package btree
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sortedSeq returns an iterator over the keys [0, numOfElements) with the key as the value.
+func sortedSeq(numOfElements int) func(yield func(int, int) bool) {
+	return func(yield func(int, int) bool) {
+		for i := 0; i < numOfElements; i++ {
+			if !yield(i, i) {
+				return
+			}
+		}
+	}
+}
+
+func Test_TreeBuildFromSorted(t *testing.T) {
+	for _, minItemsInNode := range []int{1, 2, 3, 16} {
+		for _, fillFactor := range []float64{0.1, 0.5, 0.75, 1} {
+			for _, numOfElements := range []int{0, 1, 2, 5, 10, 33, 100, 1000, 4321} {
+				name := fmt.Sprintf("minItems=%d/fill=%v/n=%d", minItemsInNode, fillFactor, numOfElements)
+				t.Run(name, func(t *testing.T) {
+					tree, err := BuildFromSorted(minItemsInNode, sortedSeq(numOfElements), fillFactor)
+					require.NoError(t, err)
+					checkTreeInvariants(t, tree)
+					require.Equal(t, numOfElements, tree.Len())
+					keys := []int{}
+					for key := range tree.All() {
+						keys = append(keys, key)
+					}
+					require.Equal(t, keyRange(0, numOfElements, 1), keys)
+				})
+			}
+		}
+	}
+}
+
+func Test_TreeBuildFromSortedFillFactor(t *testing.T) {
+	const numOfElements = 10000
+	full, err := BuildFromSorted(minItems, sortedSeq(numOfElements), 1)
+	require.NoError(t, err)
+	half, err := BuildFromSorted(minItems, sortedSeq(numOfElements), 0.5)
+	require.NoError(t, err)
+	inserted := NewTree[int, int](minItems)
+	for i := 0; i < numOfElements; i++ {
+		inserted.Put(i, i)
+	}
+
+	// Packed nodes need fewer nodes than nodes that are split as they fill up
+	assert.Less(t, full.NodeCount(), half.NodeCount())
+	assert.Less(t, full.NodeCount(), inserted.NodeCount())
+
+	// The leaves of a fully packed tree hold maxItems items, except for the ones that had to be evened out
+	fullLeaves := 0
+	var countFullLeaves func(n *Node[int, int])
+	countFullLeaves = func(n *Node[int, int]) {
+		if n.isLeaf() && len(n.items) == full.maxItems {
+			fullLeaves++
+		}
+		for _, child := range n.childNodes {
+			countFullLeaves(child)
+		}
+	}
+	countFullLeaves(full.root)
+	assert.Greater(t, fullLeaves, numOfElements/(full.maxItems+1)-full.Height())
+
+	// The tree keeps working after being built
+	full.Put(numOfElements, numOfElements)
+	full.Delete(0)
+	checkTreeInvariants(t, full)
+}
+
+func Test_TreeBuildFromSortedFunc(t *testing.T) {
+	descending := func(a, b int) int {
+		return b - a
+	}
+	reversed := func(yield func(int, int) bool) {
+		for i := 99; i >= 0; i-- {
+			if !yield(i, i) {
+				return
+			}
+		}
+	}
+	tree, err := BuildFromSortedFunc(minItems, descending, reversed, 1)
+	require.NoError(t, err)
+	checkTreeInvariants(t, tree)
+	assert.Equal(t, keyRange(99, -1, -1), collectKeys(tree.Ascend))
+
+	// Keys that are ascending by their natural order aren't sorted by the comparator
+	_, err = BuildFromSortedFunc(minItems, descending, sortedSeq(10), 1)
+	assert.ErrorIs(t, err, ErrUnsorted)
+}
+
+func Test_TreeBuildFromSortedRejectsBadInput(t *testing.T) {
+	unsorted := func(yield func(int, int) bool) {
+		for _, key := range []int{1, 2, 5, 4} {
+			if !yield(key, key) {
+				return
+			}
+		}
+	}
+	_, err := BuildFromSorted(minItems, unsorted, 1)
+	assert.ErrorIs(t, err, ErrUnsorted)
+
+	duplicates := func(yield func(int, int) bool) {
+		for _, key := range []int{1, 2, 2, 3} {
+			if !yield(key, key) {
+				return
+			}
+		}
+	}
+	_, err = BuildFromSorted(minItems, duplicates, 1)
+	assert.ErrorIs(t, err, ErrUnsorted)
+
+	_, err = BuildFromSorted(minItems, sortedSeq(10), 0)
+	assert.ErrorIs(t, err, ErrInvalidFillFactor)
+	_, err = BuildFromSorted(minItems, sortedSeq(10), 1.5)
+	assert.ErrorIs(t, err, ErrInvalidFillFactor)
+}
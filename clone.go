@@ -0,0 +1,74 @@
+package btree
+
+import "slices"
+
+// owner marks the nodes a tree is allowed to change in place. Nodes created by a tree are owned by it. Clone gives both
+// the tree and the clone new owners, so all the nodes they share become owned by neither and are copied before they're
+// changed. It isn't empty since pointers to different zero-size values may be equal.
+type owner struct {
+	_ byte
+}
+
+// Clone returns a copy of the tree in O(1). The copy shares all the nodes of the tree instead of copying them. Each of
+// the trees copies a shared node the first time it changes it, which also means copying the nodes on the path to it
+// from the root, since the parent has to point to the new copy. Nodes that aren't changed are never copied, so taking a
+// clone is cheap even for a big tree, and changing one of the trees costs about the same as before.
+//
+//	tree, clone                            tree        clone
+//	     |                                   |           |
+//	     p          clone.Put(7)             p           p'
+//	  /     \        ------>               /   \       /    \
+//	 a       b                            a     b     a      b'
+//	1,2     5,6                          1,2   5,6   1,2   5,6,7
+//
+// a is still shared by both trees after the Put.
+//
+// The tree and the clone can be changed independently, but like a tree, each of them isn't safe for concurrent use.
+func (b *Tree[K, V]) Clone() *Tree[K, V] {
+	b.owner = &owner{}
+	return b.fork()
+}
+
+// fork returns a copy of the tree with a new owner, sharing all of its nodes. Unlike Clone, it doesn't change the tree,
+// which keeps owning its nodes. It's only safe when the tree isn't going to be changed anymore.
+func (b *Tree[K, V]) fork() *Tree[K, V] {
+	return &Tree[K, V]{
+		treeConfig:    b.treeConfig,
+		root:          b.root,
+		search:        b.search,
+		nodeCount:     b.nodeCount,
+		modifications: b.modifications,
+		owner:         &owner{},
+	}
+}
+
+// mutableRoot returns the root of the tree, copying it first if it's shared with another tree.
+func (b *Tree[K, V]) mutableRoot() *Node[K, V] {
+	if b.root.owner != b.owner {
+		b.root = b.root.copyFor(b.owner)
+	}
+	return b.root
+}
+
+// mutableChild returns the child at the given index, copying it first if it's shared with another tree. The node itself
+// must already be mutable, since the copy replaces the child in it.
+func (n *Node[K, V]) mutableChild(index int) *Node[K, V] {
+	child := n.childNodes[index]
+	if child.owner != n.owner {
+		child = child.copyFor(n.owner)
+		n.childNodes[index] = child
+	}
+	return child
+}
+
+// copyFor returns a copy of the node with the given owner. The items and children are copied into new slices so that
+// changing them doesn't affect the original node. The children themselves are shared until they are changed too.
+func (n *Node[K, V]) copyFor(owner *owner) *Node[K, V] {
+	return &Node[K, V]{
+		config:     n.config,
+		owner:      owner,
+		items:      slices.Clone(n.items),
+		childNodes: slices.Clone(n.childNodes),
+		count:      n.count,
+	}
+}
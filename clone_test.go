@@ -0,0 +1,165 @@
+package btree
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_TreeClone(t *testing.T) {
+	tree := newTestIntTree(100)
+	clone := tree.Clone()
+	// Nothing is copied until one of the trees is changed
+	assert.Same(t, tree.root, clone.root)
+
+	clone.Put(1, 1)
+	clone.Put(2, 20)
+	tree.Delete(4)
+
+	_, found := tree.Get(1)
+	assert.False(t, found)
+	value, _ := tree.Get(2)
+	assert.Equal(t, 2, value)
+	assert.Equal(t, 99, tree.Len())
+
+	value, _ = clone.Get(1)
+	assert.Equal(t, 1, value)
+	value, _ = clone.Get(2)
+	assert.Equal(t, 20, value)
+	value, _ = clone.Get(4)
+	assert.Equal(t, 4, value)
+	assert.Equal(t, 101, clone.Len())
+
+	checkTreeInvariants(t, tree)
+	checkTreeInvariants(t, clone)
+}
+
+func Test_TreeCloneCopiesOnlyThePath(t *testing.T) {
+	tree := newTestIntTree(1000)
+	clone := tree.Clone()
+	clone.Put(1, 1)
+
+	shared := 0
+	var walk func(n1, n2 *Node[int, int])
+	walk = func(n1, n2 *Node[int, int]) {
+		if n1 == n2 {
+			shared += n1.nodesInSubtree()
+			return
+		}
+		for i := range n1.childNodes {
+			walk(n1.childNodes[i], n2.childNodes[i])
+		}
+	}
+	walk(tree.root, clone.root)
+	// Only the nodes on the path to the leaf of the key were copied, unless the Put split them
+	assert.Greater(t, shared, tree.NodeCount()-tree.Height()*3)
+}
+
+// Test_TreeCloneSearchStrategy checks that each tree searches the nodes it shares with its clone using its own search
+// strategy. The keys are all in the root, so a linear search for the biggest key compares it with every item, and a
+// binary search only with a few of them.
+func Test_TreeCloneSearchStrategy(t *testing.T) {
+	const numOfElements = 30
+	comparisons := 0
+	tree := NewTreeFunc[int, int](numOfElements, func(a, b int) int {
+		comparisons++
+		return cmp.Compare(a, b)
+	})
+	for i := 0; i < numOfElements; i++ {
+		tree.Put(i, i)
+	}
+	searchBiggest := func(tree *Tree[int, int]) int {
+		comparisons = 0
+		_, found := tree.Get(numOfElements - 1)
+		require.True(t, found)
+		return comparisons
+	}
+
+	clone := tree.Clone()
+	clone.SetSearchStrategy(LinearSearch)
+	require.Same(t, tree.root, clone.root)
+	assert.Equal(t, numOfElements, searchBiggest(clone))
+	assert.Less(t, searchBiggest(tree), 10)
+
+	// The strategy of a tree doesn't change when its clone copies the shared nodes
+	clone.Put(-1, -1)
+	tree.SetSearchStrategy(LinearSearch)
+	clone.SetSearchStrategy(BinarySearch)
+	assert.Equal(t, numOfElements, searchBiggest(tree))
+	assert.Less(t, searchBiggest(clone), 10)
+}
+
+func Test_TreeCloneRandom(t *testing.T) {
+	for _, minItemsInNode := range []int{1, 2, 3, 8} {
+		t.Run(fmt.Sprintf("minItems=%d", minItemsInNode), func(t *testing.T) {
+			const keySpace = 500
+			r := rand.New(rand.NewSource(int64(minItemsInNode)))
+			trees := []*Tree[int, int]{NewTree[int, int](minItemsInNode)}
+			expected := []map[int]int{{}}
+
+			for round := 0; round < 500; round++ {
+				i := r.Intn(len(trees))
+				tree, model := trees[i], expected[i]
+				switch op := r.Intn(10); {
+				case op == 0 && len(trees) < 8:
+					trees = append(trees, tree.Clone())
+					expected = append(expected, maps.Clone(model))
+				case op == 1:
+					from := r.Intn(keySpace)
+					to := from + r.Intn(keySpace/10)
+					tree.DeleteRange(from, to)
+					for key := from; key < to; key++ {
+						delete(model, key)
+					}
+				case op == 2:
+					var wb WriteBatch[int, int]
+					for j := r.Intn(100); j > 0; j-- {
+						key := r.Intn(keySpace)
+						if r.Intn(3) == 0 {
+							wb.Delete(key)
+							delete(model, key)
+						} else {
+							wb.Put(key, round)
+							model[key] = round
+						}
+					}
+					tree.Apply(&wb)
+				case op == 3:
+					if key, _, found := tree.DeleteMin(); found {
+						delete(model, key)
+					}
+				default:
+					for j := r.Intn(50); j > 0; j-- {
+						key := r.Intn(keySpace)
+						if r.Intn(3) == 0 {
+							tree.Delete(key)
+							delete(model, key)
+						} else {
+							tree.Put(key, round)
+							model[key] = round
+						}
+					}
+				}
+
+				// A change to one of the trees must not be seen by any of the others. Checking all of them is slow, so
+				// it's done every few rounds.
+				for j := range trees {
+					if j != i && round%20 != 0 {
+						continue
+					}
+					checkTreeInvariants(t, trees[j])
+					actual := map[int]int{}
+					for key, value := range trees[j].All() {
+						actual[key] = value
+					}
+					require.Equal(t, expected[j], actual)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,22 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/amit-davidson/btree"
+)
+
+func main() {
+	minimumItemsInNode := btree.DefaultMinItems
+	tree := btree.NewTree[string, string](minimumItemsInNode)
+	value := "0"
+	tree.Put(value, value)
+
+	retVal := tree.Find(value)
+	fmt.Printf("Returned value is key:%s value:%s \n", retVal.Key(), retVal.Value())
+
+	tree.Remove(value)
+
+	_, found := tree.Get(value)
+	fmt.Printf("Returned value was found: %t \n", found)
+}
@@ -0,0 +1,242 @@
+package btree
+
+import (
+	"cmp"
+	"iter"
+	"sync"
+)
+
+// ConcurrentTree is a tree that is safe for concurrent use. Reads take a shared lock, so any number of them can run in
+// parallel, while writes take an exclusive lock and run one at a time.
+//
+// Iterating can't hold the lock for the whole loop, since the loop body may be slow or may write to the tree itself.
+// Instead, the iteration methods take a snapshot of the tree using Clone, which takes O(1) under the exclusive lock,
+// and iterate over the snapshot without any lock. Writes that happen during the loop copy the nodes they change instead
+// of changing the snapshot, so the loop sees the tree exactly as it was when it started.
+type ConcurrentTree[K any, V any] struct {
+	mu   sync.RWMutex
+	tree *Tree[K, V]
+}
+
+// NewConcurrentTree creates an empty concurrent tree like NewTree.
+func NewConcurrentTree[K cmp.Ordered, V any](minItems int) *ConcurrentTree[K, V] {
+	return &ConcurrentTree[K, V]{tree: NewTree[K, V](minItems)}
+}
+
+// NewConcurrentTreeFunc creates an empty concurrent tree like NewTreeFunc.
+func NewConcurrentTreeFunc[K any, V any](minItems int, compare func(a, b K) int) *ConcurrentTree[K, V] {
+	return &ConcurrentTree[K, V]{tree: NewTreeFunc[K, V](minItems, compare)}
+}
+
+// Put adds a key to the tree, or replaces the value if the key is already in the tree, like Tree.Put.
+func (c *ConcurrentTree[K, V]) Put(key K, value V) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.tree.Put(key, value)
+}
+
+// Insert adds a key to the tree, or returns ErrKeyExists if it's already in the tree, like Tree.Insert.
+func (c *ConcurrentTree[K, V]) Insert(key K, value V) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.tree.Insert(key, value)
+}
+
+// Remove removes a key from the tree, or returns ErrKeyNotFound if it isn't in the tree, like Tree.Remove.
+func (c *ConcurrentTree[K, V]) Remove(key K) (V, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.tree.Remove(key)
+}
+
+// Delete removes a key from the tree if it's there, like Tree.Delete.
+func (c *ConcurrentTree[K, V]) Delete(key K) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.tree.Delete(key)
+}
+
+// DeleteMin removes the item with the smallest key and returns it, like Tree.DeleteMin.
+func (c *ConcurrentTree[K, V]) DeleteMin() (K, V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.tree.DeleteMin()
+}
+
+// DeleteMax removes the item with the biggest key and returns it, like Tree.DeleteMax.
+func (c *ConcurrentTree[K, V]) DeleteMax() (K, V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.tree.DeleteMax()
+}
+
+// DeleteRange removes the items with greaterOrEqual <= key < lessThan, like Tree.DeleteRange.
+func (c *ConcurrentTree[K, V]) DeleteRange(greaterOrEqual, lessThan K) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.tree.DeleteRange(greaterOrEqual, lessThan)
+}
+
+// Apply applies all the writes of the batch. The whole batch is applied under a single exclusive lock, so readers see
+// either none of its writes or all of them.
+func (c *ConcurrentTree[K, V]) Apply(wb *WriteBatch[K, V]) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.tree.Apply(wb)
+}
+
+// Get returns the value stored under the given key. The boolean is false when the key isn't in the tree.
+func (c *ConcurrentTree[K, V]) Get(key K) (V, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.tree.Get(key)
+}
+
+// Find returns a copy of the item with the given key, or nil if the key isn't in the tree.
+func (c *ConcurrentTree[K, V]) Find(key K) *Item[K, V] {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.tree.Find(key)
+}
+
+// Len returns the number of items in the tree.
+func (c *ConcurrentTree[K, V]) Len() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.tree.Len()
+}
+
+// Min returns the item with the smallest key. The boolean is false when the tree is empty.
+func (c *ConcurrentTree[K, V]) Min() (K, V, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.tree.Min()
+}
+
+// Max returns the item with the biggest key. The boolean is false when the tree is empty.
+func (c *ConcurrentTree[K, V]) Max() (K, V, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.tree.Max()
+}
+
+// Floor returns the item with the biggest key that is smaller than or equal to the given key.
+func (c *ConcurrentTree[K, V]) Floor(key K) (K, V, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.tree.Floor(key)
+}
+
+// Ceiling returns the item with the smallest key that is bigger than or equal to the given key.
+func (c *ConcurrentTree[K, V]) Ceiling(key K) (K, V, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.tree.Ceiling(key)
+}
+
+// Lower returns the item with the biggest key that is strictly smaller than the given key.
+func (c *ConcurrentTree[K, V]) Lower(key K) (K, V, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.tree.Lower(key)
+}
+
+// Higher returns the item with the smallest key that is strictly bigger than the given key.
+func (c *ConcurrentTree[K, V]) Higher(key K) (K, V, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.tree.Higher(key)
+}
+
+// Rank returns the number of keys in the tree that are smaller than the given key.
+func (c *ConcurrentTree[K, V]) Rank(key K) int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.tree.Rank(key)
+}
+
+// Select returns the item at the given position in key order, starting from 0.
+func (c *ConcurrentTree[K, V]) Select(position int) (K, V, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.tree.Select(position)
+}
+
+// Snapshot returns an immutable version of the tree as it is now. It takes O(1), and writes to the tree afterwards
+// don't affect it. The snapshot can be read from any goroutine without locking, which makes it suitable for long
+// reports that need a consistent view of the tree.
+func (c *ConcurrentTree[K, V]) Snapshot() *ImmutableTree[K, V] {
+	// Clone gives the tree a new owner, so it's a write even though the content doesn't change.
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.tree.Freeze()
+}
+
+// Ascend calls fn for every item in ascending key order until fn returns false. It iterates over a snapshot, so fn
+// may write to the tree without affecting the iteration.
+func (c *ConcurrentTree[K, V]) Ascend(fn func(key K, value V) bool) {
+	c.Snapshot().Ascend(fn)
+}
+
+// AscendRange calls fn for every item with greaterOrEqual <= key < lessThan in ascending key order until fn returns
+// false. It iterates over a snapshot like Ascend.
+func (c *ConcurrentTree[K, V]) AscendRange(greaterOrEqual, lessThan K, fn func(key K, value V) bool) {
+	c.Snapshot().AscendRange(greaterOrEqual, lessThan, fn)
+}
+
+// Descend calls fn for every item in descending key order until fn returns false. It iterates over a snapshot like
+// Ascend.
+func (c *ConcurrentTree[K, V]) Descend(fn func(key K, value V) bool) {
+	c.Snapshot().Descend(fn)
+}
+
+// All returns an iterator over all the items in ascending key order. The snapshot is taken when the loop starts, so
+// every loop over the same iterator sees the tree as it was at its own start.
+func (c *ConcurrentTree[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		c.Snapshot().All()(yield)
+	}
+}
+
+// Backward returns an iterator over all the items in descending key order. It takes a snapshot like All.
+func (c *ConcurrentTree[K, V]) Backward() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		c.Snapshot().Backward()(yield)
+	}
+}
+
+// Range returns an iterator over the items with greaterOrEqual <= key < lessThan in ascending key order. It takes a
+// snapshot like All.
+func (c *ConcurrentTree[K, V]) Range(greaterOrEqual, lessThan K) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		c.Snapshot().Range(greaterOrEqual, lessThan)(yield)
+	}
+}
+
+// Begin starts a transaction on the tree. A read-only transaction sees a snapshot of the tree like Snapshot does, and
+// doesn't block anything. A writable transaction takes the exclusive lock only to clone the tree when it begins and to
+// publish its changes when it's committed, so it doesn't block anything while it's open either, and may be abandoned
+// like a transaction on a Tree.
+//
+// Writable transactions may run at the same time, but only the first of them to commit succeeds. Commit fails with
+// ErrTxConflict if the tree was changed after the transaction began, either by the other methods or by another
+// transaction, in which case the caller may begin a new transaction and try again.
+func (c *ConcurrentTree[K, V]) Begin(writable bool) *Tx[K, V] {
+	if !writable {
+		return &Tx[K, V]{tree: c.Snapshot().tree}
+	}
+
+	c.mu.Lock()
+	base := c.tree.modifications
+	clone := c.tree.Clone()
+	c.mu.Unlock()
+	return &Tx[K, V]{
+		tree:     clone,
+		writable: true,
+		commit: func(result *Tree[K, V]) error {
+			c.mu.Lock()
+			defer c.mu.Unlock()
+			return c.tree.commitTx(base, result)
+		},
+	}
+}
@@ -0,0 +1,201 @@
+package btree
+
+import (
+	"math/rand"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// The tests in this file are meant to be run with the race detector (go test -race) as well, which reports any access
+// to the tree that isn't synchronized.
+
+func Test_ConcurrentTreeParallelWriters(t *testing.T) {
+	const numOfWriters = 8
+	const keysPerWriter = 2000
+	tree := NewConcurrentTree[int, int](minItems)
+
+	var wg sync.WaitGroup
+	for w := 0; w < numOfWriters; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r := rand.New(rand.NewSource(int64(w)))
+			// Every writer owns the keys that are equal to its number modulo numOfWriters
+			for _, i := range r.Perm(keysPerWriter) {
+				tree.Put(i*numOfWriters+w, w)
+			}
+			// Half of the keys are removed again
+			for i := 0; i < keysPerWriter; i += 2 {
+				_, err := tree.Remove(i*numOfWriters + w)
+				assert.NoError(t, err)
+			}
+		}()
+	}
+	for reader := 0; reader < 4; reader++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r := rand.New(rand.NewSource(int64(reader)))
+			for i := 0; i < 5000; i++ {
+				key := r.Intn(numOfWriters * keysPerWriter)
+				if value, found := tree.Get(key); found {
+					assert.Equal(t, key%numOfWriters, value)
+				}
+				tree.Floor(key)
+				tree.Rank(key)
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, numOfWriters*keysPerWriter/2, tree.Len())
+	checkTreeInvariants(t, tree.tree)
+	for key, value := range tree.All() {
+		require.Equal(t, key%numOfWriters, value)
+		require.Equal(t, 1, key/numOfWriters%2)
+	}
+}
+
+// Test_ConcurrentTreeSnapshotIsConsistent moves amounts between accounts using batches while readers sum all of them.
+// A reader that saw only some of the writes of a batch, or a tree that changed in the middle of its loop, would get a
+// different total.
+func Test_ConcurrentTreeSnapshotIsConsistent(t *testing.T) {
+	const numOfAccounts = 500
+	const total = numOfAccounts * 100
+	tree := NewConcurrentTree[int, int](minItems)
+	var wb WriteBatch[int, int]
+	for account := 0; account < numOfAccounts; account++ {
+		wb.Put(account, 100)
+	}
+	tree.Apply(&wb)
+
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	for w := 0; w < 4; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r := rand.New(rand.NewSource(int64(w)))
+			for i := 0; i < 500; i++ {
+				// Each writer only moves amounts between its own accounts, so the balances it read are still current
+				// when its batch is applied.
+				from := r.Intn(numOfAccounts/4)*4 + w
+				to := r.Intn(numOfAccounts/4)*4 + w
+				if from == to {
+					continue
+				}
+				fromBalance, _ := tree.Get(from)
+				toBalance, _ := tree.Get(to)
+				var wb WriteBatch[int, int]
+				wb.Put(from, fromBalance-10)
+				wb.Put(to, toBalance+10)
+				tree.Apply(&wb)
+			}
+		}()
+	}
+	var readers sync.WaitGroup
+	for reader := 0; reader < 4; reader++ {
+		readers.Add(1)
+		go func() {
+			defer readers.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				sum, accounts := 0, 0
+				for _, balance := range tree.All() {
+					sum += balance
+					accounts++
+				}
+				assert.Equal(t, total, sum)
+				assert.Equal(t, numOfAccounts, accounts)
+
+				snapshot := tree.Snapshot()
+				sum = 0
+				snapshot.Descend(func(_ int, balance int) bool {
+					sum += balance
+					return true
+				})
+				assert.Equal(t, total, sum)
+			}
+		}()
+	}
+	wg.Wait()
+	close(stop)
+	readers.Wait()
+	checkTreeInvariants(t, tree.tree)
+}
+
+func Test_ConcurrentTreeWriteWhileIterating(t *testing.T) {
+	tree := NewConcurrentTree[int, int](minItems)
+	for i := 0; i < 100; i++ {
+		tree.Put(i, i)
+	}
+
+	// Writing from the loop body doesn't deadlock and doesn't change what the loop sees
+	visited := 0
+	for key := range tree.All() {
+		tree.Delete(key)
+		tree.Put(key+1000, key)
+		visited++
+	}
+	assert.Equal(t, 100, visited)
+	assert.Equal(t, 100, tree.Len())
+	minKey, _, found := tree.Min()
+	assert.True(t, found)
+	assert.Equal(t, 1000, minKey)
+	checkTreeInvariants(t, tree.tree)
+}
+
+func Test_ConcurrentTreeStress(t *testing.T) {
+	const keySpace = 1000
+	tree := NewConcurrentTree[int, int](3)
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r := rand.New(rand.NewSource(int64(g)))
+			for i := 0; i < 2000; i++ {
+				key := r.Intn(keySpace)
+				switch r.Intn(12) {
+				case 0:
+					tree.Put(key, key)
+				case 1:
+					tree.Delete(key)
+				case 2:
+					tree.DeleteRange(key, key+10)
+				case 3:
+					var wb WriteBatch[int, int]
+					for j := 0; j < 20; j++ {
+						wb.Put(r.Intn(keySpace), j)
+					}
+					tree.Apply(&wb)
+				case 4:
+					tree.DeleteMin()
+				case 5:
+					tree.Insert(key, key)
+				case 6:
+					for range tree.Range(key, key+50) {
+					}
+				case 7:
+					tree.Snapshot().Len()
+				case 8:
+					tree.Select(r.Intn(keySpace))
+				case 9:
+					tree.Ceiling(key)
+				default:
+					tree.Get(key)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	checkTreeInvariants(t, tree.tree)
+}
@@ -0,0 +1,188 @@
+package btree
+
+// Cursor walks the items of a tree in order and can move in both directions. Like findKey, it keeps the breadcrumbs
+// from the root to the current item, so moving to the next or previous item doesn't have to search the tree again.
+// The cursor holds no copy of the tree, so it's invalidated by any change to the tree. After changing the tree, it has
+// to be positioned again using First, Last or Seek.
+type Cursor[K any, V any] struct {
+	tree *Tree[K, V]
+	// stack holds the path from the root to the current item. The last frame is the node holding the current item and
+	// its index is the index of the item. In the other frames, the index is the index of the child that was taken. An
+	// empty stack means the cursor isn't positioned on an item.
+	stack []cursorFrame[K, V]
+}
+
+type cursorFrame[K any, V any] struct {
+	node  *Node[K, V]
+	index int
+}
+
+// Cursor creates a cursor over the tree. It isn't positioned on any item until First, Last or Seek is called.
+func (b *Tree[K, V]) Cursor() *Cursor[K, V] {
+	return &Cursor[K, V]{tree: b}
+}
+
+// First moves the cursor to the item with the smallest key. It returns false if the tree is empty.
+func (c *Cursor[K, V]) First() bool {
+	c.stack = c.stack[:0]
+	c.pushLeftmost(c.tree.root)
+	return c.settle()
+}
+
+// Last moves the cursor to the item with the biggest key. It returns false if the tree is empty.
+func (c *Cursor[K, V]) Last() bool {
+	c.stack = c.stack[:0]
+	c.pushRightmost(c.tree.root)
+	return c.settle()
+}
+
+// Seek moves the cursor to the item with the given key or, if it's not in the tree, to the item with the smallest key
+// that is bigger. It returns false if there's no such item.
+func (c *Cursor[K, V]) Seek(key K) bool {
+	c.stack = c.stack[:0]
+	n := c.tree.root
+	for {
+		found, index := n.findKey(key, c.tree.search)
+		c.stack = append(c.stack, cursorFrame[K, V]{node: n, index: index})
+		if found {
+			return true
+		}
+		if n.isLeaf() {
+			if index < len(n.items) {
+				return true
+			}
+			// All the keys in the leaf are smaller, so the next item is the one after the leaf in one of the ancestors.
+			return c.climbForward()
+		}
+		n = n.childNodes[index]
+	}
+}
+
+// Next moves the cursor to the following item. It returns false, and leaves the cursor unpositioned, if the cursor was
+// on the last item or wasn't positioned.
+func (c *Cursor[K, V]) Next() bool {
+	if !c.Valid() {
+		return false
+	}
+	top := &c.stack[len(c.stack)-1]
+	if !top.node.isLeaf() {
+		// The following item is the smallest one in the subtree to the right of the current item.
+		top.index++
+		c.pushLeftmost(top.node.childNodes[top.index])
+		return true
+	}
+	top.index++
+	if top.index < len(top.node.items) {
+		return true
+	}
+	return c.climbForward()
+}
+
+// Prev moves the cursor to the preceding item. It returns false, and leaves the cursor unpositioned, if the cursor was
+// on the first item or wasn't positioned.
+func (c *Cursor[K, V]) Prev() bool {
+	if !c.Valid() {
+		return false
+	}
+	top := &c.stack[len(c.stack)-1]
+	if !top.node.isLeaf() {
+		// The preceding item is the biggest one in the subtree to the left of the current item.
+		c.pushRightmost(top.node.childNodes[top.index])
+		return true
+	}
+	top.index--
+	if top.index >= 0 {
+		return true
+	}
+	return c.climbBackward()
+}
+
+// Valid reports whether the cursor is positioned on an item.
+func (c *Cursor[K, V]) Valid() bool {
+	return len(c.stack) > 0
+}
+
+// Key returns the key of the current item, or the zero value if the cursor isn't positioned.
+func (c *Cursor[K, V]) Key() K {
+	if !c.Valid() {
+		var zero K
+		return zero
+	}
+	return c.item().key
+}
+
+// Value returns the value of the current item, or the zero value if the cursor isn't positioned.
+func (c *Cursor[K, V]) Value() V {
+	if !c.Valid() {
+		var zero V
+		return zero
+	}
+	return c.item().value
+}
+
+func (c *Cursor[K, V]) item() *Item[K, V] {
+	top := c.stack[len(c.stack)-1]
+	return top.node.items[top.index]
+}
+
+// pushLeftmost pushes the path from n to the smallest item in its subtree.
+func (c *Cursor[K, V]) pushLeftmost(n *Node[K, V]) {
+	for {
+		c.stack = append(c.stack, cursorFrame[K, V]{node: n, index: 0})
+		if n.isLeaf() {
+			return
+		}
+		n = n.childNodes[0]
+	}
+}
+
+// pushRightmost pushes the path from n to the biggest item in its subtree.
+func (c *Cursor[K, V]) pushRightmost(n *Node[K, V]) {
+	for {
+		if n.isLeaf() {
+			c.stack = append(c.stack, cursorFrame[K, V]{node: n, index: len(n.items) - 1})
+			return
+		}
+		c.stack = append(c.stack, cursorFrame[K, V]{node: n, index: len(n.childNodes) - 1})
+		n = n.childNodes[len(n.childNodes)-1]
+	}
+}
+
+// settle clears the stack when the cursor ended up on a leaf without items, which only happens when the tree is empty.
+func (c *Cursor[K, V]) settle() bool {
+	top := c.stack[len(c.stack)-1]
+	if top.index < 0 || top.index >= len(top.node.items) {
+		c.stack = c.stack[:0]
+		return false
+	}
+	return true
+}
+
+// climbForward pops the exhausted leaf and the ancestors whose last child was visited. The first ancestor that has an
+// item after the child that was taken becomes the current item.
+func (c *Cursor[K, V]) climbForward() bool {
+	c.stack = c.stack[:len(c.stack)-1]
+	for len(c.stack) > 0 {
+		top := c.stack[len(c.stack)-1]
+		if top.index < len(top.node.items) {
+			// The item after child i is item i, so the index can be used as is.
+			return true
+		}
+		c.stack = c.stack[:len(c.stack)-1]
+	}
+	return false
+}
+
+// climbBackward is the mirror image of climbForward. The item before child i is item i-1.
+func (c *Cursor[K, V]) climbBackward() bool {
+	c.stack = c.stack[:len(c.stack)-1]
+	for len(c.stack) > 0 {
+		top := &c.stack[len(c.stack)-1]
+		if top.index > 0 {
+			top.index--
+			return true
+		}
+		c.stack = c.stack[:len(c.stack)-1]
+	}
+	return false
+}
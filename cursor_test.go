@@ -0,0 +1,117 @@
+package btree
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_CursorForward(t *testing.T) {
+	tree := newTestIntTree(100)
+	c := tree.Cursor()
+
+	keys := []int{}
+	for ok := c.First(); ok; ok = c.Next() {
+		keys = append(keys, c.Key())
+		assert.Equal(t, c.Key(), c.Value())
+	}
+	assert.Equal(t, keyRange(0, 200, 2), keys)
+	assert.False(t, c.Valid())
+	assert.False(t, c.Next())
+}
+
+func Test_CursorBackward(t *testing.T) {
+	tree := newTestIntTree(100)
+	c := tree.Cursor()
+
+	keys := []int{}
+	for ok := c.Last(); ok; ok = c.Prev() {
+		keys = append(keys, c.Key())
+	}
+	assert.Equal(t, keyRange(198, -1, -2), keys)
+	assert.False(t, c.Prev())
+}
+
+func Test_CursorSeek(t *testing.T) {
+	tree := newTestIntTree(100)
+	c := tree.Cursor()
+
+	// Existing key
+	require.True(t, c.Seek(50))
+	assert.Equal(t, 50, c.Key())
+
+	// Missing key is positioned on the next one
+	require.True(t, c.Seek(51))
+	assert.Equal(t, 52, c.Key())
+	require.True(t, c.Prev())
+	assert.Equal(t, 50, c.Key())
+	require.True(t, c.Next())
+	require.True(t, c.Next())
+	assert.Equal(t, 54, c.Key())
+
+	// Every key in the tree can be reached from every seek position
+	for key := -1; key < 198; key++ {
+		require.True(t, c.Seek(key), "key %d", key)
+		expected := key + 1
+		if key%2 == 0 {
+			expected = key
+		}
+		require.Equal(t, expected, c.Key(), "key %d", key)
+	}
+
+	// Nothing after the biggest key
+	assert.False(t, c.Seek(199))
+	assert.False(t, c.Valid())
+	assert.Equal(t, 0, c.Key())
+}
+
+func Test_CursorChangeDirection(t *testing.T) {
+	tree := newTestIntTree(100)
+	c := tree.Cursor()
+
+	require.True(t, c.First())
+	for i := 0; i < 99; i++ {
+		require.True(t, c.Next())
+		require.True(t, c.Prev())
+		require.Equal(t, i*2, c.Key())
+		require.True(t, c.Next())
+	}
+	assert.Equal(t, 198, c.Key())
+	assert.False(t, c.Next())
+}
+
+func Test_CursorEmptyTree(t *testing.T) {
+	tree := NewTree[int, int](minItems)
+	c := tree.Cursor()
+
+	assert.False(t, c.First())
+	assert.False(t, c.Last())
+	assert.False(t, c.Seek(1))
+	assert.False(t, c.Next())
+	assert.False(t, c.Prev())
+}
+
+func Test_CursorMergeJoin(t *testing.T) {
+	evens := newTestIntTree(100)
+	multiplesOfThree := NewTree[int, int](minItems)
+	for i := 0; i < 200; i += 3 {
+		multiplesOfThree.Put(i, i)
+	}
+
+	left, right := evens.Cursor(), multiplesOfThree.Cursor()
+	joined := []int{}
+	leftOk, rightOk := left.First(), right.First()
+	for leftOk && rightOk {
+		switch {
+		case left.Key() < right.Key():
+			leftOk = left.Next()
+		case left.Key() > right.Key():
+			rightOk = right.Next()
+		default:
+			joined = append(joined, left.Key())
+			leftOk, rightOk = left.Next(), right.Next()
+		}
+	}
+	assert.Equal(t, keyRange(0, 200, 6), joined)
+}
@@ -0,0 +1,197 @@
+package btree
+
+// DeleteRange removes the items with greaterOrEqual <= key < lessThan and returns the number of removed items.
+// Instead of removing the keys one by one, subtrees that are entirely inside the range are detached as a whole, so
+// only the nodes on the paths to the two ends of the range and their siblings are changed. The balance of the tree is
+// then repaired once, on the way back up from those paths. The only other nodes that are visited are the nodes of the
+// detached subtrees, which are counted to keep NodeCount up to date, but none of the nodes that stay in the tree.
+func (b *Tree[K, V]) DeleteRange(greaterOrEqual, lessThan K) int {
+	if b.compare(greaterOrEqual, lessThan) >= 0 {
+		return 0
+	}
+	removed, nodes := b.mutableRoot().deleteRange(greaterOrEqual, lessThan, b.search)
+	if removed > 0 {
+		b.nodeCount += nodes + b.repairRoot()
+		b.modifications++
+	}
+	return removed
+}
+
+// deleteRange removes the items with greaterOrEqual <= key < lessThan from the subtree of the node. It returns how many
+// items were removed and by how many the number of nodes in the subtree changed. The children of the node are repaired
+// before returning, but the node itself may be left with too few items, or even none, for its parent to repair.
+//
+// The items of the node that are inside the range are items[first:last]. The children between them are inside the
+// range as well and are dropped. The children at first and last are only partially inside the range, so the range is
+// removed from them recursively. Since all the items between them were removed, they end up next to each other without
+// an item to separate them, which is fixed by joinChildren. The graph shows the node before its children are repaired.
+//
+//	               p                                                    p
+//	          10,20,30,40                                           10,11,40
+//	/      |       |       |      \      deleteRange(12,32)     /    |    |    \
+//	1,2   11,12   21,22   31,32   41,42       ------>          1,2  (empty) 32  41,42
+func (n *Node[K, V]) deleteRange(greaterOrEqual, lessThan K, search SearchStrategy) (int, int) {
+	_, first := n.findKey(greaterOrEqual, search)
+	_, last := n.findKey(lessThan, search)
+
+	if n.isLeaf() {
+		removed := last - first
+		n.items = append(n.items[:first], n.items[last:]...)
+		n.count -= removed
+		return removed, 0
+	}
+
+	if first == last {
+		// None of the items of the node is in the range, so the range is entirely inside a single child.
+		removed, nodes := n.mutableChild(first).deleteRange(greaterOrEqual, lessThan, search)
+		if removed > 0 {
+			n.count -= removed
+			nodes += n.repairChildren()
+		}
+		return removed, nodes
+	}
+
+	removed, nodes := last-first, 0
+	for _, child := range n.childNodes[first+1 : last] {
+		removed += child.count
+		nodes -= child.nodesInSubtree()
+	}
+	firstRemoved, firstNodes := n.mutableChild(first).deleteRange(greaterOrEqual, lessThan, search)
+	lastRemoved, lastNodes := n.mutableChild(last).deleteRange(greaterOrEqual, lessThan, search)
+	removed += firstRemoved + lastRemoved
+	nodes += firstNodes + lastNodes
+
+	n.items = append(n.items[:first], n.items[last:]...)
+	n.childNodes = append(n.childNodes[:first+1], n.childNodes[last:]...)
+	n.count -= removed
+	nodes += n.joinChildren(first)
+	nodes += n.repairChildren()
+	return removed, nodes
+}
+
+// joinChildren fixes a node that has two adjacent children at index and index+1 without an item between them. The
+// biggest item of the left child, or the smallest item of the right child if the left one is empty, is moved up to
+// separate them. If both of them are empty, then the right one is dropped. It returns by how many the number of nodes
+// in the subtree changed.
+func (n *Node[K, V]) joinChildren(index int) int {
+	left, right := n.childNodes[index], n.childNodes[index+1]
+	switch {
+	case left.count > 0:
+		item, nodes := n.mutableChild(index).popMax()
+		n.addItem(item, index)
+		return nodes
+	case right.count > 0:
+		item, nodes := n.mutableChild(index + 1).popMin()
+		n.addItem(item, index)
+		return nodes
+	default:
+		n.childNodes = append(n.childNodes[:index+1], n.childNodes[index+2:]...)
+		return -right.nodesInSubtree()
+	}
+}
+
+// popMax removes the item with the biggest key from the subtree of the node, which must not be empty, and returns it.
+// Like deleteRange, it repairs the children it changes but not the node itself. The biggest key is usually in the
+// rightmost leaf, but if the rightmost child has no items left, then it's the last item of the node. In that case the
+// empty child is dropped together with the item. Like joinChildren, it also returns by how many the number of nodes in
+// the subtree changed.
+func (n *Node[K, V]) popMax() (*Item[K, V], int) {
+	n.count--
+	if n.isLeaf() {
+		item := n.items[len(n.items)-1]
+		n.items = n.items[:len(n.items)-1]
+		return item, 0
+	}
+
+	lastChild := n.childNodes[len(n.childNodes)-1]
+	if lastChild.count > 0 {
+		item, nodes := n.mutableChild(len(n.childNodes) - 1).popMax()
+		return item, nodes + n.repairChildren()
+	}
+	item := n.items[len(n.items)-1]
+	n.items = n.items[:len(n.items)-1]
+	n.childNodes = n.childNodes[:len(n.childNodes)-1]
+	return item, -lastChild.nodesInSubtree()
+}
+
+// popMin is the mirror image of popMax.
+func (n *Node[K, V]) popMin() (*Item[K, V], int) {
+	n.count--
+	if n.isLeaf() {
+		item := n.items[0]
+		n.items = n.items[1:]
+		return item, 0
+	}
+
+	firstChild := n.childNodes[0]
+	if firstChild.count > 0 {
+		item, nodes := n.mutableChild(0).popMin()
+		return item, nodes + n.repairChildren()
+	}
+	item := n.items[0]
+	n.items = n.items[1:]
+	n.childNodes = n.childNodes[1:]
+	return item, -firstChild.nodesInSubtree()
+}
+
+// repairChildren rebalances the children of the node after bulk changes. Unlike split and rebalanceRemove, which fix a
+// child that is off by a single item, a child may have any number of items here. Children with too many items are
+// split. Children with too few items take items from their siblings by rotating, or are merged with them, until they
+// have enough. The subtrees of the children must already be repaired, except for nodes that are left with a single
+// child, since such a child has no sibling to rebalance with. Each rotation or merge gives the changed child another
+// sibling, so its own children are repaired again. It returns by how many the number of nodes in the subtree changed,
+// counting the siblings added by splits and the nodes removed by merges.
+func (n *Node[K, V]) repairChildren() int {
+	nodes := 0
+	for i := 0; i < len(n.childNodes); i++ {
+		child := n.childNodes[i]
+		if child.isOverPopulated() {
+			nodes += n.split(n.mutableChild(i), i)
+			continue
+		}
+		for len(n.childNodes) > 1 && n.childNodes[i].isUnderPopulated() {
+			if i > 0 && len(n.childNodes[i-1].items) > n.config.minItems {
+				rotateRight(n.mutableChild(i-1), n, n.mutableChild(i), i)
+			} else if i < len(n.childNodes)-1 && len(n.childNodes[i+1].items) > n.config.minItems {
+				rotateLeft(n.mutableChild(i), n, n.mutableChild(i+1), i)
+			} else {
+				merge(n, i)
+				nodes--
+				if i > 0 {
+					// The child was merged into its left sibling
+					i--
+				}
+			}
+			nodes += n.mutableChild(i).repairChildren()
+		}
+	}
+	return nodes
+}
+
+// repairRoot fixes the root after bulk changes. A root with too many items is split into a new root, like in Put. After
+// a batch write the new root may still have too many items, so it's split again until it fits. A root without items
+// is replaced by its only child, possibly several times, since a bulk removal can empty more than one level. Like
+// repairChildren, it returns by how many the number of nodes changed.
+func (b *Tree[K, V]) repairRoot() int {
+	nodes := 0
+	for b.root.isOverPopulated() {
+		newRoot := newNode(b.treeConfig, b.owner, []*Item[K, V]{}, []*Node[K, V]{b.root})
+		nodes += newRoot.split(newRoot.mutableChild(0), 0) + 1
+		b.root = newRoot
+	}
+	for len(b.root.items) == 0 && len(b.root.childNodes) > 0 {
+		b.root = b.root.childNodes[0]
+		nodes--
+	}
+	return nodes
+}
+
+// nodesInSubtree counts the nodes in the subtree of the node, including itself. It visits all of them, so it's only
+// used for subtrees that are dropped as a whole.
+func (n *Node[K, V]) nodesInSubtree() int {
+	nodes := 1
+	for _, child := range n.childNodes {
+		nodes += child.nodesInSubtree()
+	}
+	return nodes
+}
@@ -0,0 +1,115 @@
+package btree
+
+import (
+	"fmt"
+	"math/rand"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_TreeDeleteRange(t *testing.T) {
+	tree := newTestIntTree(100)
+
+	assert.Equal(t, 20, tree.DeleteRange(10, 50))
+	assert.Equal(t, 80, tree.Len())
+	checkTreeInvariants(t, tree)
+
+	keys := []int{}
+	for key := range tree.All() {
+		keys = append(keys, key)
+	}
+	assert.Equal(t, append(keyRange(0, 10, 2), keyRange(50, 200, 2)...), keys)
+
+	// Nothing left in the range
+	assert.Equal(t, 0, tree.DeleteRange(10, 50))
+	// Empty and inverted ranges
+	assert.Equal(t, 0, tree.DeleteRange(60, 60))
+	assert.Equal(t, 0, tree.DeleteRange(70, 60))
+	assert.Equal(t, 80, tree.Len())
+}
+
+func Test_TreeDeleteRangeEverything(t *testing.T) {
+	tree := newTestIntTree(1000)
+
+	assert.Equal(t, 1000, tree.DeleteRange(-1, 2000))
+	checkTreeInvariants(t, tree)
+	assert.Equal(t, 0, tree.Len())
+	assert.Equal(t, 1, tree.Height())
+	assert.Equal(t, 1, tree.NodeCount())
+
+	// The tree is still usable afterwards
+	tree.Put(1, 1)
+	value, found := tree.Get(1)
+	assert.True(t, found)
+	assert.Equal(t, 1, value)
+}
+
+func Test_TreeDeleteRangeRandom(t *testing.T) {
+	for _, minItemsInNode := range []int{1, 2, 3, 8} {
+		t.Run(fmt.Sprintf("minItems=%d", minItemsInNode), func(t *testing.T) {
+			const numOfElements = 1000
+			r := rand.New(rand.NewSource(int64(minItemsInNode)))
+
+			for round := 0; round < 20; round++ {
+				tree := NewTree[int, int](minItemsInNode)
+				expected := map[int]bool{}
+				for _, key := range r.Perm(numOfElements) {
+					tree.Put(key, key)
+					expected[key] = true
+				}
+
+				for len(expected) > 0 {
+					from := r.Intn(numOfElements)
+					to := from + r.Intn(numOfElements/5)
+					expectedRemoved := 0
+					for key := from; key < to; key++ {
+						if expected[key] {
+							expectedRemoved++
+							delete(expected, key)
+						}
+					}
+					require.Equal(t, expectedRemoved, tree.DeleteRange(from, to))
+					require.Equal(t, len(expected), tree.Len())
+					checkTreeInvariants(t, tree)
+
+					if len(expected) < numOfElements/10 {
+						break
+					}
+				}
+				for key := range expected {
+					_, found := tree.Get(key)
+					require.True(t, found)
+				}
+			}
+		})
+	}
+}
+
+// Test_TreeNodeCountAfterBulkWrites checks that the node count is kept exact by DeleteRange and Apply, so that
+// NodeCount only reads it. Readers of a tree that isn't changed anymore may call it at the same time, so it's meant to
+// be run with the race detector as well.
+func Test_TreeNodeCountAfterBulkWrites(t *testing.T) {
+	tree := newTestIntTree(1000)
+	tree.DeleteRange(100, 900)
+	var wb WriteBatch[int, int]
+	for i := 1; i < 2000; i += 4 {
+		wb.Put(i, i)
+		wb.Delete(i + 1)
+	}
+	tree.Apply(&wb)
+	checkTreeInvariants(t, tree)
+
+	nodeCount := tree.NodeCount()
+	var readers sync.WaitGroup
+	for reader := 0; reader < 4; reader++ {
+		readers.Add(1)
+		go func() {
+			defer readers.Done()
+			assert.Equal(t, nodeCount, tree.NodeCount())
+		}()
+	}
+	readers.Wait()
+}
@@ -0,0 +1,165 @@
+package btree
+
+import (
+	"cmp"
+	"iter"
+)
+
+// ImmutableTree is a version of a tree that never changes. Put, Remove and Apply leave the receiver as it is and return
+// a new version with the change instead. The new version is built by copying only the nodes on the paths that were
+// changed, from the root down to the leaves, and shares all the other nodes with the receiver. Keeping every version
+// around costs only the nodes that differ between them.
+//
+//	    v1                                    v1          v2
+//	     |                                     |           |
+//	     p          v2 := v1.Put(7)            p           p'
+//	  /     \         ------>                /   \       /    \
+//	 a       b                              a     b     a      b'
+//	1,2     5,6                            1,2   5,6   1,2   5,6,7
+//
+// Since a version is never changed, it's safe to read it and to create new versions from it from many goroutines at
+// once without any locking.
+type ImmutableTree[K any, V any] struct {
+	// tree is never changed once the version is created. New versions are forks of it, so it keeps owning its nodes
+	// but none of them is ever changed in place.
+	tree *Tree[K, V]
+}
+
+// NewImmutableTree creates an empty immutable tree like NewTree.
+func NewImmutableTree[K cmp.Ordered, V any](minItems int) *ImmutableTree[K, V] {
+	return &ImmutableTree[K, V]{tree: NewTree[K, V](minItems)}
+}
+
+// NewImmutableTreeFunc creates an empty immutable tree like NewTreeFunc.
+func NewImmutableTreeFunc[K any, V any](minItems int, compare func(a, b K) int) *ImmutableTree[K, V] {
+	return &ImmutableTree[K, V]{tree: NewTreeFunc[K, V](minItems, compare)}
+}
+
+// Freeze returns an immutable version with the content of the tree. It takes O(1) like Clone, and the tree can keep
+// being changed afterwards without affecting the version.
+func (b *Tree[K, V]) Freeze() *ImmutableTree[K, V] {
+	return &ImmutableTree[K, V]{tree: b.Clone()}
+}
+
+// Thaw returns a regular tree with the content of the version. It takes O(1) like Clone, and changing the tree doesn't
+// affect the version.
+func (i *ImmutableTree[K, V]) Thaw() *Tree[K, V] {
+	return i.tree.fork()
+}
+
+// Put returns a new version with the key added, or with its value replaced if the key is already in the tree.
+func (i *ImmutableTree[K, V]) Put(key K, value V) *ImmutableTree[K, V] {
+	tree := i.tree.fork()
+	tree.Put(key, value)
+	return &ImmutableTree[K, V]{tree: tree}
+}
+
+// Remove returns a new version without the key. If the key isn't in the tree, then nothing is copied, and the receiver
+// itself is returned together with false.
+func (i *ImmutableTree[K, V]) Remove(key K) (*ImmutableTree[K, V], bool) {
+	if _, found := i.tree.Get(key); !found {
+		return i, false
+	}
+	tree := i.tree.fork()
+	tree.Remove(key)
+	return &ImmutableTree[K, V]{tree: tree}, true
+}
+
+// Apply returns a new version with all the writes of the batch applied, like Tree.Apply.
+func (i *ImmutableTree[K, V]) Apply(wb *WriteBatch[K, V]) *ImmutableTree[K, V] {
+	tree := i.tree.fork()
+	tree.Apply(wb)
+	return &ImmutableTree[K, V]{tree: tree}
+}
+
+// Get returns the value stored under the given key. The boolean is false when the key isn't in the tree.
+func (i *ImmutableTree[K, V]) Get(key K) (V, bool) {
+	return i.tree.Get(key)
+}
+
+// Find returns a copy of the item with the given key, or nil if the key isn't in the tree.
+func (i *ImmutableTree[K, V]) Find(key K) *Item[K, V] {
+	return i.tree.Find(key)
+}
+
+// Len returns the number of items in the tree.
+func (i *ImmutableTree[K, V]) Len() int {
+	return i.tree.Len()
+}
+
+// Min returns the item with the smallest key. The boolean is false when the tree is empty.
+func (i *ImmutableTree[K, V]) Min() (K, V, bool) {
+	return i.tree.Min()
+}
+
+// Max returns the item with the biggest key. The boolean is false when the tree is empty.
+func (i *ImmutableTree[K, V]) Max() (K, V, bool) {
+	return i.tree.Max()
+}
+
+// Floor returns the item with the biggest key that is smaller than or equal to the given key.
+func (i *ImmutableTree[K, V]) Floor(key K) (K, V, bool) {
+	return i.tree.Floor(key)
+}
+
+// Ceiling returns the item with the smallest key that is bigger than or equal to the given key.
+func (i *ImmutableTree[K, V]) Ceiling(key K) (K, V, bool) {
+	return i.tree.Ceiling(key)
+}
+
+// Lower returns the item with the biggest key that is strictly smaller than the given key.
+func (i *ImmutableTree[K, V]) Lower(key K) (K, V, bool) {
+	return i.tree.Lower(key)
+}
+
+// Higher returns the item with the smallest key that is strictly bigger than the given key.
+func (i *ImmutableTree[K, V]) Higher(key K) (K, V, bool) {
+	return i.tree.Higher(key)
+}
+
+// Rank returns the number of keys in the tree that are smaller than the given key.
+func (i *ImmutableTree[K, V]) Rank(key K) int {
+	return i.tree.Rank(key)
+}
+
+// Select returns the item at the given position in key order, starting from 0.
+func (i *ImmutableTree[K, V]) Select(position int) (K, V, bool) {
+	return i.tree.Select(position)
+}
+
+// Ascend calls fn for every item in ascending key order until fn returns false.
+func (i *ImmutableTree[K, V]) Ascend(fn func(key K, value V) bool) {
+	i.tree.Ascend(fn)
+}
+
+// AscendRange calls fn for every item with greaterOrEqual <= key < lessThan in ascending key order until fn returns
+// false.
+func (i *ImmutableTree[K, V]) AscendRange(greaterOrEqual, lessThan K, fn func(key K, value V) bool) {
+	i.tree.AscendRange(greaterOrEqual, lessThan, fn)
+}
+
+// Descend calls fn for every item in descending key order until fn returns false.
+func (i *ImmutableTree[K, V]) Descend(fn func(key K, value V) bool) {
+	i.tree.Descend(fn)
+}
+
+// All returns an iterator over all the items in ascending key order. Unlike the iterators of a Tree, it stays valid no
+// matter which versions are created while it's consumed.
+func (i *ImmutableTree[K, V]) All() iter.Seq2[K, V] {
+	return i.tree.All()
+}
+
+// Backward returns an iterator over all the items in descending key order.
+func (i *ImmutableTree[K, V]) Backward() iter.Seq2[K, V] {
+	return i.tree.Backward()
+}
+
+// Range returns an iterator over the items with greaterOrEqual <= key < lessThan in ascending key order.
+func (i *ImmutableTree[K, V]) Range(greaterOrEqual, lessThan K) iter.Seq2[K, V] {
+	return i.tree.Range(greaterOrEqual, lessThan)
+}
+
+// Cursor creates a cursor over the version. Since the version never changes, the cursor is never invalidated.
+func (i *ImmutableTree[K, V]) Cursor() *Cursor[K, V] {
+	return i.tree.Cursor()
+}
@@ -0,0 +1,130 @@
+package btree
+
+import (
+	"fmt"
+	"maps"
+	"math/rand"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func immutableTreeItems[K comparable, V any](tree *ImmutableTree[K, V]) map[K]V {
+	items := map[K]V{}
+	for key, value := range tree.All() {
+		items[key] = value
+	}
+	return items
+}
+
+func Test_ImmutableTreePut(t *testing.T) {
+	v0 := NewImmutableTree[int, string](minItems)
+	v1 := v0.Put(1, "a")
+	v2 := v1.Put(2, "b")
+	v3 := v2.Put(1, "c")
+
+	assert.Equal(t, 0, v0.Len())
+	assert.Equal(t, map[int]string{1: "a"}, immutableTreeItems(v1))
+	assert.Equal(t, map[int]string{1: "a", 2: "b"}, immutableTreeItems(v2))
+	assert.Equal(t, map[int]string{1: "c", 2: "b"}, immutableTreeItems(v3))
+}
+
+func Test_ImmutableTreeRemove(t *testing.T) {
+	v1 := newTestIntTree(100).Freeze()
+
+	v2, found := v1.Remove(4)
+	assert.True(t, found)
+	_, found = v2.Get(4)
+	assert.False(t, found)
+	value, found := v1.Get(4)
+	assert.True(t, found)
+	assert.Equal(t, 4, value)
+
+	// Removing a missing key doesn't create a new version
+	v3, found := v2.Remove(4)
+	assert.False(t, found)
+	assert.Same(t, v2, v3)
+}
+
+func Test_ImmutableTreeFreezeAndThaw(t *testing.T) {
+	tree := newTestIntTree(100)
+	frozen := tree.Freeze()
+	tree.Put(1, 1)
+	_, found := frozen.Get(1)
+	assert.False(t, found)
+
+	thawed := frozen.Thaw()
+	thawed.Delete(0)
+	checkTreeInvariants(t, thawed)
+	_, found = frozen.Get(0)
+	assert.True(t, found)
+	assert.Equal(t, 100, frozen.Len())
+	assert.Equal(t, 99, thawed.Len())
+}
+
+func Test_ImmutableTreeVersionsRandom(t *testing.T) {
+	for _, minItemsInNode := range []int{1, 2, 8} {
+		t.Run(fmt.Sprintf("minItems=%d", minItemsInNode), func(t *testing.T) {
+			const keySpace = 300
+			r := rand.New(rand.NewSource(int64(minItemsInNode)))
+			versions := []*ImmutableTree[int, int]{NewImmutableTree[int, int](minItemsInNode)}
+			expected := []map[int]int{{}}
+
+			for round := 0; round < 300; round++ {
+				// New versions are created from random older versions, not only from the latest one
+				i := r.Intn(len(versions))
+				version, model := versions[i], maps.Clone(expected[i])
+				key := r.Intn(keySpace)
+				if r.Intn(3) == 0 {
+					_, inModel := model[key]
+					var found bool
+					version, found = version.Remove(key)
+					require.Equal(t, inModel, found)
+					delete(model, key)
+				} else {
+					version = version.Put(key, round)
+					model[key] = round
+				}
+				versions = append(versions, version)
+				expected = append(expected, model)
+			}
+
+			for i, version := range versions {
+				checkTreeInvariants(t, version.tree)
+				require.Equal(t, expected[i], immutableTreeItems(version))
+			}
+		})
+	}
+}
+
+func Test_ImmutableTreeConcurrentVersions(t *testing.T) {
+	base := newTestIntTree(1000).Freeze()
+
+	var wg sync.WaitGroup
+	results := make([]*ImmutableTree[int, int], 8)
+	for g := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			version := base
+			for i := 0; i < 200; i++ {
+				version = version.Put((g+1)*10000+i, i)
+				version, _ = version.Remove(i * 2)
+				version.Get(i)
+			}
+			results[g] = version
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, 1000, base.Len())
+	for g, version := range results {
+		checkTreeInvariants(t, version.tree)
+		assert.Equal(t, 1000, version.Len())
+		value, found := version.Get((g+1)*10000 + 199)
+		assert.True(t, found)
+		assert.Equal(t, 199, value)
+	}
+}
@@ -0,0 +1,121 @@
+package btree
+
+import "iter"
+
+// Ascend calls fn for every item in the tree in ascending key order. The iteration stops when fn returns false.
+func (b *Tree[K, V]) Ascend(fn func(key K, value V) bool) {
+	b.root.ascend(nil, nil, b.search, fn)
+}
+
+// AscendRange calls fn for every item with greaterOrEqual <= key < lessThan in ascending key order. The iteration stops
+// when fn returns false.
+func (b *Tree[K, V]) AscendRange(greaterOrEqual, lessThan K, fn func(key K, value V) bool) {
+	b.root.ascend(&greaterOrEqual, &lessThan, b.search, fn)
+}
+
+// AscendGreaterOrEqual calls fn for every item with key >= pivot in ascending key order. The iteration stops when fn
+// returns false.
+func (b *Tree[K, V]) AscendGreaterOrEqual(pivot K, fn func(key K, value V) bool) {
+	b.root.ascend(&pivot, nil, b.search, fn)
+}
+
+// Descend calls fn for every item in the tree in descending key order. The iteration stops when fn returns false.
+func (b *Tree[K, V]) Descend(fn func(key K, value V) bool) {
+	b.root.descend(nil, b.search, fn)
+}
+
+// DescendLessOrEqual calls fn for every item with key <= pivot in descending key order. The iteration stops when fn
+// returns false.
+func (b *Tree[K, V]) DescendLessOrEqual(pivot K, fn func(key K, value V) bool) {
+	b.root.descend(&pivot, b.search, fn)
+}
+
+// All returns an iterator over all the items of the tree in ascending key order. The iterator reads the tree while
+// it's being consumed, so the tree must not be changed until the loop is over.
+func (b *Tree[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		b.root.ascend(nil, nil, b.search, yield)
+	}
+}
+
+// Backward returns an iterator over all the items of the tree in descending key order.
+func (b *Tree[K, V]) Backward() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		b.root.descend(nil, b.search, yield)
+	}
+}
+
+// Range returns an iterator over the items with greaterOrEqual <= key < lessThan in ascending key order.
+func (b *Tree[K, V]) Range(greaterOrEqual, lessThan K) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		b.root.ascend(&greaterOrEqual, &lessThan, b.search, yield)
+	}
+}
+
+// ascend walks the subtree in order, starting at the first key >= start and stopping before the first key >= stop. A
+// nil bound means the walk isn't limited in that direction. Only the first child that is visited can hold keys smaller
+// than start, so the following children are walked without it. It returns false once the walk should stop, either
+// because fn asked to or because stop was reached.
+func (n *Node[K, V]) ascend(start, stop *K, search SearchStrategy, fn func(key K, value V) bool) bool {
+	firstIndex := 0
+	skipFirstChild := false
+	if start != nil {
+		// If the key itself is in the node, then the child before it only holds smaller keys.
+		skipFirstChild, firstIndex = n.findKey(*start, search)
+	}
+
+	if !n.isLeaf() && !skipFirstChild {
+		if !n.childNodes[firstIndex].ascend(start, stop, search, fn) {
+			return false
+		}
+	}
+	for i := firstIndex; i < len(n.items); i++ {
+		item := n.items[i]
+		if stop != nil && n.config.compare(item.key, *stop) >= 0 {
+			return false
+		}
+		if !fn(item.key, item.value) {
+			return false
+		}
+		if !n.isLeaf() {
+			if !n.childNodes[i+1].ascend(nil, stop, search, fn) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// descend is the mirror image of ascend. It walks the subtree in reverse order, starting at the last key <= start. A
+// nil start means the walk starts at the last key.
+func (n *Node[K, V]) descend(start *K, search SearchStrategy, fn func(key K, value V) bool) bool {
+	lastIndex := len(n.items)
+	skipLastChild := false
+	if start != nil {
+		found, index := n.findKey(*start, search)
+		lastIndex = index
+		if found {
+			// The key itself is visited, and the child after it only holds bigger keys.
+			lastIndex = index + 1
+			skipLastChild = true
+		}
+	}
+
+	if !n.isLeaf() && !skipLastChild {
+		if !n.childNodes[lastIndex].descend(start, search, fn) {
+			return false
+		}
+	}
+	for i := lastIndex - 1; i >= 0; i-- {
+		item := n.items[i]
+		if !fn(item.key, item.value) {
+			return false
+		}
+		if !n.isLeaf() {
+			if !n.childNodes[i].descend(nil, search, fn) {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,158 @@
+package btree
+
+import (
+	"maps"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestIntTree returns a tree that holds the even numbers in [0, 2*numOfElements). The odd numbers are used to check
+// the behaviour for keys that aren't in the tree.
+func newTestIntTree(numOfElements int) *Tree[int, int] {
+	tree := NewTree[int, int](minItems)
+	for i := 0; i < numOfElements; i++ {
+		tree.Put(i*2, i*2)
+	}
+	return tree
+}
+
+func collectKeys(walk func(fn func(key, value int) bool)) []int {
+	keys := []int{}
+	walk(func(key, value int) bool {
+		keys = append(keys, key)
+		return true
+	})
+	return keys
+}
+
+// keyRange returns the keys from "from" up to, but not including, "to".
+func keyRange(from, to, step int) []int {
+	keys := []int{}
+	for i := from; (step > 0 && i < to) || (step < 0 && i > to); i += step {
+		keys = append(keys, i)
+	}
+	return keys
+}
+
+func Test_TreeAscend(t *testing.T) {
+	tree := newTestIntTree(100)
+	assert.Equal(t, keyRange(0, 200, 2), collectKeys(tree.Ascend))
+
+	empty := NewTree[int, int](minItems)
+	assert.Equal(t, []int{}, collectKeys(empty.Ascend))
+}
+
+func Test_TreeDescend(t *testing.T) {
+	tree := newTestIntTree(100)
+	assert.Equal(t, keyRange(198, -1, -2), collectKeys(tree.Descend))
+}
+
+func Test_TreeAscendRange(t *testing.T) {
+	tree := newTestIntTree(100)
+
+	ascendRange := func(greaterOrEqual, lessThan int) []int {
+		return collectKeys(func(fn func(key, value int) bool) {
+			tree.AscendRange(greaterOrEqual, lessThan, fn)
+		})
+	}
+	assert.Equal(t, keyRange(10, 50, 2), ascendRange(10, 50))
+	assert.Equal(t, keyRange(12, 52, 2), ascendRange(11, 51))
+	assert.Equal(t, keyRange(0, 200, 2), ascendRange(-10, 1000))
+	assert.Equal(t, []int{}, ascendRange(50, 50))
+	assert.Equal(t, []int{}, ascendRange(60, 50))
+}
+
+func Test_TreeAscendGreaterOrEqual(t *testing.T) {
+	tree := newTestIntTree(100)
+
+	ascend := func(pivot int) []int {
+		return collectKeys(func(fn func(key, value int) bool) {
+			tree.AscendGreaterOrEqual(pivot, fn)
+		})
+	}
+	assert.Equal(t, keyRange(100, 200, 2), ascend(100))
+	assert.Equal(t, keyRange(102, 200, 2), ascend(101))
+	assert.Equal(t, []int{}, ascend(199))
+}
+
+func Test_TreeDescendLessOrEqual(t *testing.T) {
+	tree := newTestIntTree(100)
+
+	descend := func(pivot int) []int {
+		return collectKeys(func(fn func(key, value int) bool) {
+			tree.DescendLessOrEqual(pivot, fn)
+		})
+	}
+	assert.Equal(t, keyRange(100, -1, -2), descend(100))
+	assert.Equal(t, keyRange(100, -1, -2), descend(101))
+	assert.Equal(t, []int{}, descend(-1))
+}
+
+func Test_TreeIterationStopsEarly(t *testing.T) {
+	tree := newTestIntTree(100)
+
+	keys := []int{}
+	tree.Ascend(func(key, value int) bool {
+		keys = append(keys, key)
+		return len(keys) < 5
+	})
+	assert.Equal(t, []int{0, 2, 4, 6, 8}, keys)
+
+	keys = []int{}
+	tree.DescendLessOrEqual(51, func(key, value int) bool {
+		keys = append(keys, key)
+		return len(keys) < 3
+	})
+	assert.Equal(t, []int{50, 48, 46}, keys)
+}
+
+func Test_TreeAll(t *testing.T) {
+	tree := newTestIntTree(100)
+
+	keys := []int{}
+	for key, value := range tree.All() {
+		assert.Equal(t, key, value)
+		keys = append(keys, key)
+	}
+	assert.Equal(t, keyRange(0, 200, 2), keys)
+
+	// Breaking out of the loop stops the iteration
+	keys = []int{}
+	for key := range tree.All() {
+		if key == 10 {
+			break
+		}
+		keys = append(keys, key)
+	}
+	assert.Equal(t, []int{0, 2, 4, 6, 8}, keys)
+
+	// The iterator composes with the standard library helpers
+	collected := maps.Collect(tree.All())
+	assert.Len(t, collected, 100)
+	assert.Equal(t, 42, collected[42])
+}
+
+func Test_TreeBackward(t *testing.T) {
+	tree := newTestIntTree(100)
+
+	keys := []int{}
+	for key := range tree.Backward() {
+		keys = append(keys, key)
+	}
+	assert.Equal(t, keyRange(198, -1, -2), keys)
+}
+
+func Test_TreeRange(t *testing.T) {
+	tree := newTestIntTree(100)
+
+	keys := []int{}
+	for key := range tree.Range(11, 21) {
+		keys = append(keys, key)
+	}
+	assert.Equal(t, []int{12, 14, 16, 18, 20}, keys)
+
+	values := slices.Sorted(maps.Values(maps.Collect(tree.Range(0, 6))))
+	assert.Equal(t, []int{0, 2, 4}, values)
+}
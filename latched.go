@@ -0,0 +1,448 @@
+package btree
+
+import (
+	"cmp"
+	"sync"
+	"sync/atomic"
+)
+
+// LatchedTree is a tree that is safe for concurrent use and lets writers change different parts of the tree at the
+// same time. Instead of a single lock for the whole tree like ConcurrentTree, every node has its own latch, and an
+// operation holds only the latches of the nodes it may still change.
+//
+// The latches are taken from the root down, a technique known as latch coupling or crabbing. A reader latches the
+// child before releasing the parent, so it holds at most two latches at a time. A writer keeps the latches of the nodes
+// on its path, since a split or a merge at the bottom may go all the way up. But once it latches a child that is safe,
+// meaning it has room for another item on Put or an item to spare on Remove, nothing below the child can change the
+// nodes above it, so the writer releases all of their latches. Most nodes are safe, so writers usually hold only the
+// latches of the last few levels, and writers in independent subtrees don't wait for each other.
+//
+//	          p
+//	          4
+//	   /             \
+//	  a                b
+//	1,2,3            10,20
+//	           /       |        \
+//	         5,6     12,15    21,22,23,24  <- c
+//
+// With maxItems=4, Put(25) latches p and then b. b has room for another item, so p is released and a writer in a can
+// run at the same time. c is full, so b stays latched until the split of c is done, since the split adds an item to b.
+//
+// Readers don't take any latch at all unless writers keep changing the nodes they read. See getOptimistic.
+//
+// The subtree counts of the nodes would require latching every node on the path, so a LatchedTree doesn't keep them
+// and doesn't offer Rank and Select. Len is kept by a separate counter.
+type LatchedTree[K any, V any] struct {
+	// rootLatch protects the root pointer, which changes when the root is split or collapses. Writers replace the root
+	// while holding it, but readers that don't take it may load the root at any time, so the pointer itself is atomic.
+	rootLatch sync.RWMutex
+	root      atomic.Pointer[latchedNode[K, V]]
+	minItems  int
+	maxItems  int
+	compare   func(a, b K) int
+	length    atomic.Int64
+}
+
+// latchedNode is a node of a LatchedTree. It's laid out like Node, without the subtree count, together with the latch
+// that protects it and the version that optimistic readers use to read it without the latch.
+type latchedNode[K any, V any] struct {
+	latch sync.RWMutex
+	// version allows the readers to search the node without taking its latch. See getOptimistic.
+	version    atomic.Uint64
+	items      slots[Item[K, V]]
+	childNodes slots[latchedNode[K, V]]
+}
+
+// slots holds the items or the children of a latchedNode. Optimistic readers read them while a writer changes them, so
+// instead of a slice that is resized and shifted, a node allocates all the slots it may ever need up front, and every
+// slot as well as the number of used ones is accessed atomically. A reader that reads them in the middle of a write
+// may see a mix of the old and the new contents, or an empty slot, but never a torn value.
+type slots[T any] struct {
+	values []atomic.Pointer[T]
+	count  atomic.Int32
+}
+
+func (s *slots[T]) len() int {
+	return int(s.count.Load())
+}
+
+func (s *slots[T]) get(index int) *T {
+	return s.values[index].Load()
+}
+
+func (s *slots[T]) set(index int, value *T) {
+	s.values[index].Store(value)
+}
+
+// insert shifts the values from the given index on one slot to the right, and stores the value at the index.
+func (s *slots[T]) insert(index int, value *T) {
+	count := s.len()
+	for i := count; i > index; i-- {
+		s.set(i, s.get(i-1))
+	}
+	s.set(index, value)
+	s.count.Store(int32(count + 1))
+}
+
+// remove removes the value at the given index and shifts the values after it one slot to the left.
+func (s *slots[T]) remove(index int) *T {
+	count := s.len()
+	value := s.get(index)
+	for i := index; i < count-1; i++ {
+		s.set(i, s.get(i+1))
+	}
+	s.set(count-1, nil)
+	s.count.Store(int32(count - 1))
+	return value
+}
+
+// moveTo moves the values from the given index on to the end of other.
+func (s *slots[T]) moveTo(index int, other *slots[T]) {
+	count, target := s.len(), other.len()
+	for i := index; i < count; i++ {
+		other.set(target, s.get(i))
+		s.set(i, nil)
+		target++
+	}
+	s.count.Store(int32(index))
+	other.count.Store(int32(target))
+}
+
+// NewLatchedTree creates an empty latched tree whose nodes hold between minItems and minItems*2 items, like NewTree.
+func NewLatchedTree[K cmp.Ordered, V any](minItems int) *LatchedTree[K, V] {
+	return NewLatchedTreeFunc[K, V](minItems, cmp.Compare[K])
+}
+
+// NewLatchedTreeFunc creates an empty latched tree like NewTreeFunc.
+func NewLatchedTreeFunc[K any, V any](minItems int, compare func(a, b K) int) *LatchedTree[K, V] {
+	l := &LatchedTree[K, V]{
+		minItems: minItems,
+		maxItems: minItems * 2,
+		compare:  compare,
+	}
+	l.root.Store(l.newNode(true))
+	return l
+}
+
+// newNode creates an empty node with room for one item more than maxItems, and for one child more than that unless
+// it's a leaf, which is the most a node holds before it's split.
+func (l *LatchedTree[K, V]) newNode(leaf bool) *latchedNode[K, V] {
+	n := &latchedNode[K, V]{}
+	n.items.values = make([]atomic.Pointer[Item[K, V]], l.maxItems+1)
+	if !leaf {
+		n.childNodes.values = make([]atomic.Pointer[latchedNode[K, V]], l.maxItems+2)
+	}
+	return n
+}
+
+// isLeaf reports whether the node is a leaf. A node never stops being a leaf or becomes one, so unlike the number of
+// children, this doesn't change under optimistic readers.
+func (n *latchedNode[K, V]) isLeaf() bool {
+	return n.childNodes.values == nil
+}
+
+// findKey searches the items of the node like Node.findKey. An optimistic reader may search a node while a writer
+// changes it and find an empty slot. The search stops there, since the result is thrown away anyway once the reader
+// sees the new version of the node.
+func (l *LatchedTree[K, V]) findKey(n *latchedNode[K, V], key K) (bool, int) {
+	low, high := 0, n.items.len()
+	for low < high {
+		middle := int(uint(low+high) >> 1)
+		item := n.items.get(middle)
+		if item == nil {
+			return false, low
+		}
+		switch c := l.compare(item.key, key); {
+		case c == 0:
+			return true, middle
+		case c < 0:
+			low = middle + 1
+		default:
+			high = middle
+		}
+	}
+	return false, low
+}
+
+// latchPath holds the latches of a writer. nodes are the latched nodes on the path from the topmost node that may still
+// change down to the current node, and indexes are the index of each of them in the one before it.
+type latchPath[K any, V any] struct {
+	// rootLatch is the latch of the root pointer while it's held, or nil once it was released.
+	rootLatch *sync.RWMutex
+	nodes     []*latchedNode[K, V]
+	indexes   []int
+}
+
+// descend adds a latched node to the path. If the node is safe, then the latches of the nodes above it are released
+// first, since nothing below it can change them anymore.
+func (p *latchPath[K, V]) descend(n *latchedNode[K, V], index int, safe bool) {
+	if safe {
+		p.release()
+	}
+	p.nodes = append(p.nodes, n)
+	p.indexes = append(p.indexes, index)
+}
+
+// release releases all the latches that are still held.
+func (p *latchPath[K, V]) release() {
+	if p.rootLatch != nil {
+		p.rootLatch.Unlock()
+		p.rootLatch = nil
+	}
+	for _, n := range p.nodes {
+		n.latch.Unlock()
+	}
+	p.nodes = p.nodes[:0]
+	p.indexes = p.indexes[:0]
+}
+
+// lockRoot latches the root pointer and the root itself for a writer.
+func (l *LatchedTree[K, V]) lockRoot() (*latchedNode[K, V], *latchPath[K, V]) {
+	l.rootLatch.Lock()
+	root := l.root.Load()
+	root.latch.Lock()
+	return root, &latchPath[K, V]{rootLatch: &l.rootLatch}
+}
+
+// Get returns the value stored under the given key. The boolean is false when the key isn't in the tree. It searches
+// the tree without taking any latch, and starts again if a writer changed one of the nodes it read. If that keeps
+// happening, then it falls back to taking the shared latches of at most two nodes at a time.
+func (l *LatchedTree[K, V]) Get(key K) (V, bool) {
+	for i := 0; i < optimisticReadAttempts; i++ {
+		if value, found, ok := l.getOptimistic(key); ok {
+			return value, found
+		}
+	}
+	return l.getLatched(key)
+}
+
+func (l *LatchedTree[K, V]) getLatched(key K) (V, bool) {
+	l.rootLatch.RLock()
+	n := l.root.Load()
+	n.latch.RLock()
+	l.rootLatch.RUnlock()
+	for {
+		found, index := l.findKey(n, key)
+		if found {
+			value := n.items.get(index).value
+			n.latch.RUnlock()
+			return value, true
+		}
+		if n.isLeaf() {
+			n.latch.RUnlock()
+			var zero V
+			return zero, false
+		}
+		child := n.childNodes.get(index)
+		child.latch.RLock()
+		n.latch.RUnlock()
+		n = child
+	}
+}
+
+// Len returns the number of items in the tree.
+func (l *LatchedTree[K, V]) Len() int {
+	return int(l.length.Load())
+}
+
+// Put adds a key to the tree, or replaces the value if the key is already in the tree. It returns the previous value
+// and whether it was replaced. The nodes are split on the way back up like in Tree.Put, but only the nodes that are
+// still latched can be split, which are exactly the ones that may have too many items.
+func (l *LatchedTree[K, V]) Put(key K, value V) (V, bool) {
+	item := newItem(key, value)
+	n, path := l.lockRoot()
+	defer path.release()
+	path.descend(n, 0, n.items.len() < l.maxItems)
+	for {
+		found, index := l.findKey(n, key)
+		if found {
+			oldItem := n.items.get(index)
+			n.beginWrite()
+			n.items.set(index, item)
+			n.endWrite()
+			return oldItem.value, true
+		}
+		if n.isLeaf() {
+			// Any of the nodes that are still latched may be split from here on
+			for _, node := range path.nodes {
+				node.beginWrite()
+			}
+			n.items.insert(index, item)
+			break
+		}
+		child := n.childNodes.get(index)
+		child.latch.Lock()
+		path.descend(child, index, child.items.len() < l.maxItems)
+		n = child
+	}
+	l.length.Add(1)
+
+	for i := len(path.nodes) - 1; i > 0; i-- {
+		if path.nodes[i].items.len() > l.maxItems {
+			l.split(path.nodes[i-1], path.nodes[i], path.indexes[i])
+		}
+	}
+	// The root can only be too big if it wasn't safe, in which case the root pointer is still latched
+	if root := l.root.Load(); path.rootLatch != nil && root.items.len() > l.maxItems {
+		newRoot := l.newNode(false)
+		newRoot.childNodes.insert(0, root)
+		l.split(newRoot, root, 0)
+		l.root.Store(newRoot)
+	}
+	for _, node := range path.nodes {
+		node.endWrite()
+	}
+	var zero V
+	return zero, false
+}
+
+// split moves the upper half of the items of the child at the given index to a new sibling, and the item in the
+// middle up to the parent, like Node.split. A single item is added at a time, so the child has exactly one item too
+// many.
+//
+//	   parent                                      parent
+//	     3                                          3,6
+//	  /     \            ------>             /       |       \
+//	1,2    4,5,6,7,8                       1,2      4,5      7,8
+func (l *LatchedTree[K, V]) split(parent, child *latchedNode[K, V], index int) {
+	sibling := l.newNode(child.isLeaf())
+	child.items.moveTo(l.minItems+1, &sibling.items)
+	middle := child.items.remove(l.minItems)
+	if !child.isLeaf() {
+		child.childNodes.moveTo(l.minItems+1, &sibling.childNodes)
+	}
+	parent.items.insert(index, middle)
+	parent.childNodes.insert(index+1, sibling)
+}
+
+// Delete removes a key from the tree. It reports whether the key was in the tree.
+func (l *LatchedTree[K, V]) Delete(key K) bool {
+	_, err := l.Remove(key)
+	return err == nil
+}
+
+// Remove removes a key from the tree and returns the value it held, or ErrKeyNotFound if the key isn't in the tree. If
+// the key is in an internal node, then it's replaced by the biggest item of its left subtree like in Tree.Remove.
+//
+// Once the key is found, none of the nodes below it is released, even the safe ones. The item that replaces the key
+// moves up past them, so their versions have to change as well. Otherwise, an optimistic reader that passed the found
+// node before the item moved up, and got to the leaf after it, would see the same versions all the way down and report
+// that the item isn't in the tree.
+func (l *LatchedTree[K, V]) Remove(key K) (V, error) {
+	n, path := l.lockRoot()
+	defer path.release()
+	// The root may have as few as a single item, and only loses a level when its last item is removed
+	path.descend(n, 0, n.isLeaf() || n.items.len() > 1)
+
+	var foundNode *latchedNode[K, V]
+	foundIndex := -1
+	for {
+		childIndex := n.childNodes.len() - 1 // Below the found node, the biggest item is looked for
+		if foundNode == nil {
+			found, index := l.findKey(n, key)
+			if found {
+				foundNode, foundIndex = n, index
+			} else if n.isLeaf() {
+				var zero V
+				return zero, ErrKeyNotFound
+			}
+			childIndex = index
+		}
+		if n.isLeaf() {
+			break
+		}
+		child := n.childNodes.get(childIndex)
+		child.latch.Lock()
+		path.descend(child, childIndex, foundNode == nil && child.items.len() > l.minItems)
+		n = child
+	}
+
+	for _, node := range path.nodes {
+		node.beginWrite()
+	}
+	removedItem := foundNode.items.get(foundIndex)
+	if foundNode == n {
+		n.items.remove(foundIndex)
+	} else {
+		foundNode.items.set(foundIndex, n.items.remove(n.items.len()-1))
+	}
+	l.length.Add(-1)
+
+	for i := len(path.nodes) - 1; i > 0; i-- {
+		if path.nodes[i].items.len() < l.minItems {
+			l.rebalanceLatched(path.nodes[i-1], path.indexes[i])
+		}
+	}
+	// The root can only be emptied if it wasn't safe, in which case the root pointer is still latched
+	if root := l.root.Load(); path.rootLatch != nil && root.items.len() == 0 && !root.isLeaf() {
+		l.root.Store(root.childNodes.get(0))
+	}
+	for _, node := range path.nodes {
+		node.endWrite()
+	}
+	return removedItem.value, nil
+}
+
+// rebalanceLatched rebalances the child at the given index like rebalanceRemove. The parent and the child are already
+// latched by the writer, but the siblings that it may rotate with or merge into are not, so they are latched around it.
+// Since every latch is taken by a writer that already holds the parent, this can't deadlock with writers coming down
+// from the root.
+func (l *LatchedTree[K, V]) rebalanceLatched(parent *latchedNode[K, V], index int) {
+	var siblings []*latchedNode[K, V]
+	if index > 0 {
+		siblings = append(siblings, parent.childNodes.get(index-1))
+	}
+	if index < parent.childNodes.len()-1 {
+		siblings = append(siblings, parent.childNodes.get(index+1))
+	}
+	for _, sibling := range siblings {
+		sibling.latch.Lock()
+		sibling.beginWrite()
+	}
+	l.rebalance(parent, index)
+	for _, sibling := range siblings {
+		sibling.endWrite()
+		sibling.latch.Unlock()
+	}
+}
+
+// rebalance gives the child at the given index, which has one item too few, an item from one of its siblings by
+// rotating, or merges it with one of them if neither has an item to spare, like rebalanceRemove.
+func (l *LatchedTree[K, V]) rebalance(parent *latchedNode[K, V], index int) {
+	child := parent.childNodes.get(index)
+	if index > 0 {
+		if left := parent.childNodes.get(index - 1); left.items.len() > l.minItems {
+			// Rotate right: the item before the child moves down to it and the last item of left takes its place
+			child.items.insert(0, parent.items.get(index-1))
+			parent.items.set(index-1, left.items.remove(left.items.len()-1))
+			if !left.isLeaf() {
+				child.childNodes.insert(0, left.childNodes.remove(left.childNodes.len()-1))
+			}
+			return
+		}
+	}
+	if index < parent.childNodes.len()-1 {
+		if right := parent.childNodes.get(index + 1); right.items.len() > l.minItems {
+			// Rotate left: the item after the child moves down to it and the first item of right takes its place
+			child.items.insert(child.items.len(), parent.items.get(index))
+			parent.items.set(index, right.items.remove(0))
+			if !right.isLeaf() {
+				child.childNodes.insert(child.childNodes.len(), right.childNodes.remove(0))
+			}
+			return
+		}
+	}
+
+	// Merge the child with its right sibling, or with its left one if it's the last child. The item between them moves
+	// down, and the right one of them is removed.
+	if index == parent.childNodes.len()-1 {
+		index--
+	}
+	left, right := parent.childNodes.get(index), parent.childNodes.get(index+1)
+	left.items.insert(left.items.len(), parent.items.remove(index))
+	right.items.moveTo(0, &left.items)
+	if !right.isLeaf() {
+		right.childNodes.moveTo(0, &left.childNodes)
+	}
+	parent.childNodes.remove(index + 1)
+}
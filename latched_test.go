@@ -0,0 +1,179 @@
+package btree
+
+import (
+	"fmt"
+	"math/rand"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// checkLatchedTreeInvariants checks the tree like checkTreeInvariants. It also checks that no node is left in the
+// middle of a write, and that exactly the used slots of every node, which optimistic readers search, are filled.
+func checkLatchedTreeInvariants[K any, V any](t *testing.T, tree *LatchedTree[K, V]) {
+	t.Helper()
+	root := tree.root.Load()
+	leafDepth := -1
+	count := 0
+	var prev *K
+	var walk func(n *latchedNode[K, V], depth int)
+	walk = func(n *latchedNode[K, V], depth int) {
+		if n != root {
+			require.GreaterOrEqual(t, n.items.len(), tree.minItems)
+		}
+		require.LessOrEqual(t, n.items.len(), tree.maxItems)
+		_, stable := n.stableVersion()
+		require.True(t, stable)
+		for i := range n.items.values {
+			require.Equal(t, i < n.items.len(), n.items.get(i) != nil)
+		}
+		for i := range n.childNodes.values {
+			require.Equal(t, i < n.childNodes.len(), n.childNodes.get(i) != nil)
+		}
+		count += n.items.len()
+		if n.isLeaf() {
+			if leafDepth == -1 {
+				leafDepth = depth
+			}
+			require.Equal(t, leafDepth, depth)
+		} else {
+			require.Equal(t, n.items.len()+1, n.childNodes.len())
+		}
+		for i := 0; i < n.items.len(); i++ {
+			item := n.items.get(i)
+			if !n.isLeaf() {
+				walk(n.childNodes.get(i), depth+1)
+			}
+			if prev != nil {
+				require.Less(t, tree.compare(*prev, item.key), 0)
+			}
+			key := item.key
+			prev = &key
+		}
+		if !n.isLeaf() {
+			walk(n.childNodes.get(n.childNodes.len()-1), depth+1)
+		}
+	}
+	walk(root, 0)
+	require.Equal(t, count, tree.Len())
+}
+
+func Test_LatchedTree(t *testing.T) {
+	tree := NewLatchedTree[int, string](minItems)
+	for i := 0; i < 100; i++ {
+		_, replaced := tree.Put(i, "a")
+		assert.False(t, replaced)
+	}
+	old, replaced := tree.Put(50, "b")
+	assert.True(t, replaced)
+	assert.Equal(t, "a", old)
+
+	value, found := tree.Get(50)
+	assert.True(t, found)
+	assert.Equal(t, "b", value)
+	_, found = tree.Get(100)
+	assert.False(t, found)
+
+	value, err := tree.Remove(50)
+	assert.NoError(t, err)
+	assert.Equal(t, "b", value)
+	_, err = tree.Remove(50)
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+	assert.False(t, tree.Delete(50))
+	assert.Equal(t, 99, tree.Len())
+	checkLatchedTreeInvariants(t, tree)
+
+	for i := 0; i < 100; i++ {
+		tree.Delete(i)
+	}
+	assert.Equal(t, 0, tree.Len())
+	checkLatchedTreeInvariants(t, tree)
+}
+
+func Test_LatchedTreeRandom(t *testing.T) {
+	for _, minItemsInNode := range []int{1, 2, 3, 8} {
+		t.Run(fmt.Sprintf("minItems=%d", minItemsInNode), func(t *testing.T) {
+			const keySpace = 1000
+			r := rand.New(rand.NewSource(int64(minItemsInNode)))
+			tree := NewLatchedTree[int, int](minItemsInNode)
+			expected := map[int]int{}
+
+			for i := 0; i < 20000; i++ {
+				key := r.Intn(keySpace)
+				if r.Intn(2) == 0 {
+					_, found := expected[key]
+					require.Equal(t, found, tree.Delete(key))
+					delete(expected, key)
+				} else {
+					tree.Put(key, i)
+					expected[key] = i
+				}
+				if i%1000 == 0 {
+					checkLatchedTreeInvariants(t, tree)
+				}
+			}
+			checkLatchedTreeInvariants(t, tree)
+			for key, value := range expected {
+				actual, found := tree.Get(key)
+				require.True(t, found)
+				require.Equal(t, value, actual)
+			}
+		})
+	}
+}
+
+// Test_LatchedTreeConcurrentWriters runs writers on interleaved keys, so they often work in the same nodes, together
+// with readers. It's meant to be run with the race detector as well.
+func Test_LatchedTreeConcurrentWriters(t *testing.T) {
+	for _, minItemsInNode := range []int{1, 2, 8} {
+		t.Run(fmt.Sprintf("minItems=%d", minItemsInNode), func(t *testing.T) {
+			const numOfWriters = 8
+			const keysPerWriter = 2000
+			tree := NewLatchedTree[int, int](minItemsInNode)
+
+			var wg sync.WaitGroup
+			for w := 0; w < numOfWriters; w++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					r := rand.New(rand.NewSource(int64(w)))
+					// Every writer owns the keys that are equal to its number modulo numOfWriters
+					for _, i := range r.Perm(keysPerWriter) {
+						tree.Put(i*numOfWriters+w, w)
+					}
+					for _, i := range r.Perm(keysPerWriter) {
+						if i%2 == 0 {
+							assert.True(t, tree.Delete(i*numOfWriters+w))
+						}
+					}
+				}()
+			}
+			for reader := 0; reader < 4; reader++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					r := rand.New(rand.NewSource(int64(reader)))
+					for i := 0; i < 10000; i++ {
+						key := r.Intn(numOfWriters * keysPerWriter)
+						if value, found := tree.Get(key); found {
+							assert.Equal(t, key%numOfWriters, value)
+						}
+					}
+				}()
+			}
+			wg.Wait()
+
+			assert.Equal(t, numOfWriters*keysPerWriter/2, tree.Len())
+			checkLatchedTreeInvariants(t, tree)
+			for key := 0; key < numOfWriters*keysPerWriter; key++ {
+				value, found := tree.Get(key)
+				require.Equal(t, key/numOfWriters%2 == 1, found, "key %d", key)
+				if found {
+					require.Equal(t, key%numOfWriters, value)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,325 @@
+package btree
+
+import (
+	"cmp"
+	"iter"
+	"sync"
+	"sync/atomic"
+)
+
+// mvccChunkSize is the number of keys that a reader of an MVCCTree, or its garbage collection, handles while holding
+// the lock. Long scans are split into chunks so that writers can commit between them.
+const mvccChunkSize = 256
+
+// MVCCTree is a tree that keeps several versions of every value, so that readers see a stable view of the tree while
+// writers keep committing, without taking snapshots and without blocking the writers.
+//
+// Every commit gets a timestamp, which is one more than the timestamp of the previous commit. The value of every item
+// is a chain of versions of the value, from the newest to the oldest, each with the timestamp of the commit that wrote
+// it. Removing a key adds a version that marks it as deleted, a tombstone, instead of removing the item. A reader is
+// opened at the timestamp of the last commit, T, and for every key it sees the newest version whose timestamp is at
+// most T. The versions that were committed after it are simply skipped.
+//
+//	key 7:   ts 9: deleted  -->  ts 6: "b"  -->  ts 2: "a"
+//
+//	reader at T=1 doesn't see the key, at T=2..5 it sees "a", at T=6..8 it sees "b", and from T=9 on it's deleted.
+//
+// Since a reader at T ignores everything that was committed after T, it doesn't have to hold the lock for the whole
+// scan. It takes the shared lock only to read the version chains of a chunk of keys, and writers can commit between
+// the chunks. The version chains are never changed once they're in the tree, a commit replaces the item with a new
+// chain that points to the old one, so a reader can go over the chains it read after releasing the lock.
+//
+// Old versions are removed by GC once no open reader can see them anymore.
+type MVCCTree[K any, V any] struct {
+	mu   sync.RWMutex
+	tree *Tree[K, *version[V]]
+	// clock is the timestamp of the last commit.
+	clock atomic.Uint64
+	// readers counts the open readers by their timestamps.
+	readersMu sync.Mutex
+	readers   map[uint64]int
+}
+
+// version is a version of a value in an MVCCTree. Versions are never changed once they're in the tree.
+type version[V any] struct {
+	timestamp uint64
+	value     V
+	deleted   bool
+	// next is the previous version of the value, which has a smaller timestamp.
+	next *version[V]
+}
+
+// at returns the version that a reader at the given timestamp sees, or nil if the key didn't exist at the time.
+func (v *version[V]) at(timestamp uint64) *version[V] {
+	for v != nil && v.timestamp > timestamp {
+		v = v.next
+	}
+	return v
+}
+
+// prune returns the chain without the versions that no reader at the horizon or after it can see, together with the
+// number of removed versions. The newest version at the horizon is kept, unless it's a tombstone, since a missing
+// version means the same. The versions before it are copied rather than changed, since readers may be going over them.
+func (v *version[V]) prune(horizon uint64) (*version[V], int) {
+	if v == nil {
+		return nil, 0
+	}
+	if v.timestamp > horizon {
+		next, removed := v.next.prune(horizon)
+		if removed == 0 {
+			return v, 0
+		}
+		pruned := *v
+		pruned.next = next
+		return &pruned, removed
+	}
+
+	removed := 0
+	for older := v.next; older != nil; older = older.next {
+		removed++
+	}
+	if v.deleted {
+		return nil, removed + 1
+	}
+	if removed == 0 {
+		return v, 0
+	}
+	pruned := *v
+	pruned.next = nil
+	return &pruned, removed
+}
+
+// NewMVCCTree creates an empty multi-version tree like NewTree.
+func NewMVCCTree[K cmp.Ordered, V any](minItems int) *MVCCTree[K, V] {
+	return newMVCCTree(NewTree[K, *version[V]](minItems))
+}
+
+// NewMVCCTreeFunc creates an empty multi-version tree like NewTreeFunc.
+func NewMVCCTreeFunc[K any, V any](minItems int, compare func(a, b K) int) *MVCCTree[K, V] {
+	return newMVCCTree(NewTreeFunc[K, *version[V]](minItems, compare))
+}
+
+func newMVCCTree[K any, V any](tree *Tree[K, *version[V]]) *MVCCTree[K, V] {
+	return &MVCCTree[K, V]{
+		tree:    tree,
+		readers: map[uint64]int{},
+	}
+}
+
+// Timestamp returns the timestamp of the last commit, which is 0 before the first one.
+func (m *MVCCTree[K, V]) Timestamp() uint64 {
+	return m.clock.Load()
+}
+
+// Commit applies all the writes of the batch as a single commit and returns its timestamp. Readers that are already
+// open don't see any of the writes, and readers that are opened afterwards see all of them. Like Tree.Apply, when the
+// same key is written more than once, the last write wins. An empty batch isn't a commit, so it returns the timestamp
+// of the last commit without advancing the clock.
+func (m *MVCCTree[K, V]) Commit(wb *WriteBatch[K, V]) uint64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(wb.ops) == 0 {
+		return m.clock.Load()
+	}
+	timestamp := m.clock.Load() + 1
+	var versions WriteBatch[K, *version[V]]
+	for _, op := range wb.ops {
+		// A tombstone is added even if the key isn't in the tree, since an earlier write in the batch may add it. GC
+		// removes such tombstones.
+		previous, _ := m.tree.Get(op.item.key)
+		versions.Put(op.item.key, &version[V]{
+			timestamp: timestamp,
+			value:     op.item.value,
+			deleted:   op.delete,
+			next:      previous,
+		})
+	}
+	m.tree.Apply(&versions)
+	// The timestamp is published only once the commit is applied, so a reader that is opened in the meantime
+	// doesn't see it.
+	m.clock.Store(timestamp)
+	return timestamp
+}
+
+// Put commits a single write of the key and returns the timestamp of the commit.
+func (m *MVCCTree[K, V]) Put(key K, value V) uint64 {
+	var wb WriteBatch[K, V]
+	wb.Put(key, value)
+	return m.Commit(&wb)
+}
+
+// Delete commits the removal of the key and returns the timestamp of the commit.
+func (m *MVCCTree[K, V]) Delete(key K) uint64 {
+	var wb WriteBatch[K, V]
+	wb.Delete(key)
+	return m.Commit(&wb)
+}
+
+// MVCCReader reads an MVCCTree as it was at the timestamp the reader was opened at. It must be closed once it's done,
+// since the versions it may see are kept until then.
+type MVCCReader[K any, V any] struct {
+	tree      *MVCCTree[K, V]
+	timestamp uint64
+	closed    bool
+}
+
+// BeginRead opens a reader at the timestamp of the last commit.
+func (m *MVCCTree[K, V]) BeginRead() *MVCCReader[K, V] {
+	// The timestamp is read and registered together, so that GC can't pick a horizon after the timestamp is read
+	// and before the reader is registered.
+	m.readersMu.Lock()
+	defer m.readersMu.Unlock()
+	timestamp := m.clock.Load()
+	m.readers[timestamp]++
+	return &MVCCReader[K, V]{tree: m, timestamp: timestamp}
+}
+
+// Timestamp returns the timestamp the reader sees the tree at.
+func (r *MVCCReader[K, V]) Timestamp() uint64 {
+	return r.timestamp
+}
+
+// Close closes the reader, which allows GC to remove the versions that only it could see. The reader must not be
+// used afterwards. Closing a reader more than once does nothing.
+func (r *MVCCReader[K, V]) Close() {
+	if r.closed {
+		return
+	}
+	r.closed = true
+	m := r.tree
+	m.readersMu.Lock()
+	defer m.readersMu.Unlock()
+	m.readers[r.timestamp]--
+	if m.readers[r.timestamp] == 0 {
+		delete(m.readers, r.timestamp)
+	}
+}
+
+// Get returns the value the key had at the timestamp of the reader. The boolean is false when the key didn't exist
+// at the time.
+func (r *MVCCReader[K, V]) Get(key K) (V, bool) {
+	r.tree.mu.RLock()
+	chain, _ := r.tree.tree.Get(key)
+	r.tree.mu.RUnlock()
+
+	v := chain.at(r.timestamp)
+	if v == nil || v.deleted {
+		var zero V
+		return zero, false
+	}
+	return v.value, true
+}
+
+// All returns an iterator over all the items at the timestamp of the reader in ascending key order. The lock is held
+// only while a chunk of the keys is read, never while the loop body runs, so the loop body may commit to the tree.
+func (r *MVCCReader[K, V]) All() iter.Seq2[K, V] {
+	return r.scan(nil, nil)
+}
+
+// Range returns an iterator over the items with greaterOrEqual <= key < lessThan at the timestamp of the reader, in
+// ascending key order. It reads the tree in chunks like All.
+func (r *MVCCReader[K, V]) Range(greaterOrEqual, lessThan K) iter.Seq2[K, V] {
+	return r.scan(&greaterOrEqual, &lessThan)
+}
+
+func (r *MVCCReader[K, V]) scan(start, stop *K) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		r.tree.chunks(start, stop, false, func(keys []K, chains []*version[V]) bool {
+			for i, chain := range chains {
+				if v := chain.at(r.timestamp); v != nil && !v.deleted {
+					if !yield(keys[i], v.value) {
+						return false
+					}
+				}
+			}
+			return true
+		})
+	}
+}
+
+// chunks reads the version chains of the keys with start <= key < stop in chunks of mvccChunkSize keys, and calls fn
+// with every chunk until it returns false. The lock is held while a chunk is read. When exclusive is true, it's the
+// exclusive lock, and it's held while fn runs as well, so fn may change the tree. Every chunk resumes from the last key
+// of the previous one, so the keys that were added or removed in the meantime don't throw it off.
+func (m *MVCCTree[K, V]) chunks(start, stop *K, exclusive bool, fn func(keys []K, chains []*version[V]) bool) {
+	lock, unlock := m.mu.RLock, m.mu.RUnlock
+	if exclusive {
+		lock, unlock = m.mu.Lock, m.mu.Unlock
+	}
+
+	keys := make([]K, 0, mvccChunkSize)
+	chains := make([]*version[V], 0, mvccChunkSize)
+	var last *K
+	for {
+		keys, chains = keys[:0], chains[:0]
+		from := start
+		if last != nil {
+			from = last
+		}
+
+		lock()
+		m.tree.root.ascend(from, stop, m.tree.search, func(key K, chain *version[V]) bool {
+			// The last key of the previous chunk was already handled.
+			if last != nil && m.tree.compare(key, *last) == 0 {
+				return true
+			}
+			keys = append(keys, key)
+			chains = append(chains, chain)
+			return len(keys) < mvccChunkSize
+		})
+		if !exclusive {
+			unlock()
+		}
+		more := fn(keys, chains)
+		if exclusive {
+			unlock()
+		}
+
+		if !more || len(keys) < mvccChunkSize {
+			return
+		}
+		key := keys[len(keys)-1]
+		last = &key
+	}
+}
+
+// GC removes the versions that no open reader can see anymore and returns the number of removed versions. The horizon
+// is the timestamp of the oldest open reader, or of the last commit when no reader is open. For every key, the newest
+// version at the horizon is kept along with the ones after it, and the older versions are removed. Keys that are
+// deleted at the horizon lose the tombstone as well, and are removed from the tree once no version is left.
+//
+// Like the readers, GC goes over the tree in chunks, and writers can commit between them. Commits after the horizon
+// are unaffected, since only versions at or before it are removed.
+func (m *MVCCTree[K, V]) GC() int {
+	horizon := m.horizon()
+	removed := 0
+	m.chunks(nil, nil, true, func(keys []K, chains []*version[V]) bool {
+		var wb WriteBatch[K, *version[V]]
+		for i, chain := range chains {
+			pruned, n := chain.prune(horizon)
+			if n == 0 {
+				continue
+			}
+			removed += n
+			if pruned == nil {
+				wb.Delete(keys[i])
+			} else {
+				wb.Put(keys[i], pruned)
+			}
+		}
+		m.tree.Apply(&wb)
+		return true
+	})
+	return removed
+}
+
+// horizon returns the oldest timestamp an open reader can see the tree at.
+func (m *MVCCTree[K, V]) horizon() uint64 {
+	m.readersMu.Lock()
+	defer m.readersMu.Unlock()
+	horizon := m.clock.Load()
+	for timestamp := range m.readers {
+		horizon = min(horizon, timestamp)
+	}
+	return horizon
+}
@@ -0,0 +1,310 @@
+package btree
+
+import (
+	"math/rand"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countVersions returns the number of versions of all the keys in the tree, including tombstones.
+func countVersions[K any, V any](m *MVCCTree[K, V]) int {
+	count := 0
+	m.tree.Ascend(func(_ K, chain *version[V]) bool {
+		for ; chain != nil; chain = chain.next {
+			count++
+		}
+		return true
+	})
+	return count
+}
+
+func Test_MVCCReaderSeesStableView(t *testing.T) {
+	m := NewMVCCTree[int, string](minItems)
+	assert.Equal(t, uint64(1), m.Put(1, "a"))
+	m.Put(2, "x")
+
+	reader := m.BeginRead()
+	defer reader.Close()
+	assert.Equal(t, uint64(2), reader.Timestamp())
+
+	m.Put(1, "b")
+	m.Delete(2)
+	m.Put(3, "c")
+	assert.Equal(t, uint64(5), m.Timestamp())
+
+	// The reader doesn't see anything that was committed after it was opened
+	value, found := reader.Get(1)
+	assert.True(t, found)
+	assert.Equal(t, "a", value)
+	value, found = reader.Get(2)
+	assert.True(t, found)
+	assert.Equal(t, "x", value)
+	_, found = reader.Get(3)
+	assert.False(t, found)
+	assert.Equal(t, []int{1, 2}, collectKeys(func(fn func(key, value int) bool) {
+		for key := range reader.All() {
+			if !fn(key, 0) {
+				return
+			}
+		}
+	}))
+
+	// A new reader sees all of them
+	latest := m.BeginRead()
+	defer latest.Close()
+	value, _ = latest.Get(1)
+	assert.Equal(t, "b", value)
+	_, found = latest.Get(2)
+	assert.False(t, found)
+	value, _ = latest.Get(3)
+	assert.Equal(t, "c", value)
+}
+
+func Test_MVCCCommitIsAtomic(t *testing.T) {
+	m := NewMVCCTree[int, int](minItems)
+	var wb WriteBatch[int, int]
+	for i := 0; i < 100; i++ {
+		wb.Put(i, i)
+	}
+	// The last write of a key wins, even when a key that isn't in the tree is deleted after being written
+	wb.Delete(5)
+	wb.Put(7, 70)
+	timestamp := m.Commit(&wb)
+	assert.Equal(t, uint64(1), timestamp)
+
+	reader := m.BeginRead()
+	defer reader.Close()
+	_, found := reader.Get(5)
+	assert.False(t, found)
+	value, _ := reader.Get(7)
+	assert.Equal(t, 70, value)
+
+	sum := 0
+	for _, value := range reader.All() {
+		sum += value
+	}
+	assert.Equal(t, 99*100/2-5-7+70, sum)
+}
+
+func Test_MVCCCommitEmptyBatch(t *testing.T) {
+	m := NewMVCCTree[int, int](minItems)
+	assert.Equal(t, uint64(0), m.Commit(&WriteBatch[int, int]{}))
+	m.Put(1, 1)
+
+	// An empty batch doesn't advance the clock
+	var wb WriteBatch[int, int]
+	assert.Equal(t, uint64(1), m.Commit(&wb))
+	assert.Equal(t, uint64(1), m.Timestamp())
+	wb.Put(2, 2)
+	assert.Equal(t, uint64(2), m.Commit(&wb))
+}
+
+// Test_MVCCScanWhileCommitting commits from the loop body of a scan that spans several chunks. The scan doesn't hold
+// the lock while the loop body runs, and it doesn't see any of the commits.
+func Test_MVCCScanWhileCommitting(t *testing.T) {
+	m := NewMVCCTree[int, int](minItems)
+	const numOfKeys = mvccChunkSize * 4
+	for i := 0; i < numOfKeys; i++ {
+		m.Put(i*2, i*2)
+	}
+
+	reader := m.BeginRead()
+	defer reader.Close()
+	var keys []int
+	for key, value := range reader.Range(10, numOfKeys*2-10) {
+		assert.Equal(t, key, value)
+		keys = append(keys, key)
+		// Remove the following keys, add keys between them, and change the ones that were already read
+		m.Delete(key + 2)
+		m.Put(key+1, key+1)
+		m.Put(key, -1)
+	}
+	assert.Equal(t, keyRange(10, numOfKeys*2-10, 2), keys)
+
+	latest := m.BeginRead()
+	defer latest.Close()
+	_, found := latest.Get(numOfKeys*2 - 10)
+	assert.False(t, found)
+	value, _ := latest.Get(11)
+	assert.Equal(t, 11, value)
+	checkTreeInvariants(t, m.tree)
+}
+
+func Test_MVCCGC(t *testing.T) {
+	m := NewMVCCTree[int, int](minItems)
+	for i := 0; i < 10; i++ {
+		m.Put(i, -1)
+		m.Put(i, 0)
+	}
+	old := m.BeginRead()
+	for i := 0; i < 10; i++ {
+		m.Put(i, 1)
+		m.Put(i, 2)
+	}
+	m.Delete(0)
+	assert.Equal(t, 41, countVersions(m))
+
+	// The old reader still sees the versions it was opened at, so only the ones before them can be removed
+	assert.Equal(t, 10, m.GC())
+	assert.Equal(t, 31, countVersions(m))
+	value, found := old.Get(0)
+	assert.True(t, found)
+	assert.Equal(t, 0, value)
+
+	// Once it's closed, only the latest versions are kept, and the deleted key is gone with its tombstone
+	old.Close()
+	old.Close()
+	assert.Equal(t, 22, m.GC())
+	assert.Equal(t, 9, countVersions(m))
+	assert.Equal(t, 9, m.tree.Len())
+	assert.Equal(t, 0, m.GC())
+
+	reader := m.BeginRead()
+	defer reader.Close()
+	_, found = reader.Get(0)
+	assert.False(t, found)
+	for i := 1; i < 10; i++ {
+		value, _ := reader.Get(i)
+		assert.Equal(t, 2, value)
+	}
+	checkTreeInvariants(t, m.tree)
+}
+
+func Test_MVCCGCKeepsVersionsOfOpenReaders(t *testing.T) {
+	m := NewMVCCTree[int, int](minItems)
+	r := rand.New(rand.NewSource(0))
+	const numOfKeys = mvccChunkSize * 2
+
+	type openReader struct {
+		reader   *MVCCReader[int, int]
+		expected map[int]int
+	}
+	var readers []openReader
+	current := map[int]int{}
+	for round := 0; round < 200; round++ {
+		var wb WriteBatch[int, int]
+		for i := 0; i < 20; i++ {
+			key := r.Intn(numOfKeys)
+			if r.Intn(3) == 0 {
+				wb.Delete(key)
+				delete(current, key)
+			} else {
+				wb.Put(key, round)
+				current[key] = round
+			}
+		}
+		m.Commit(&wb)
+
+		switch r.Intn(4) {
+		case 0:
+			expected := map[int]int{}
+			for key, value := range current {
+				expected[key] = value
+			}
+			readers = append(readers, openReader{m.BeginRead(), expected})
+		case 1:
+			if len(readers) > 0 {
+				i := r.Intn(len(readers))
+				readers[i].reader.Close()
+				readers = append(readers[:i], readers[i+1:]...)
+			}
+		case 2:
+			m.GC()
+		}
+
+		for _, open := range readers {
+			seen := map[int]int{}
+			for key, value := range open.reader.All() {
+				seen[key] = value
+			}
+			require.Equal(t, open.expected, seen)
+		}
+	}
+	checkTreeInvariants(t, m.tree)
+}
+
+// Test_MVCCConcurrent moves amounts between accounts while readers sum all of them and GC runs in the background. A
+// reader that saw only some of the writes of a commit, or versions that GC removed under it, would get a different
+// total.
+func Test_MVCCConcurrent(t *testing.T) {
+	const numOfAccounts = mvccChunkSize * 2
+	const total = numOfAccounts * 100
+	m := NewMVCCTree[int, int](minItems)
+	var wb WriteBatch[int, int]
+	for account := 0; account < numOfAccounts; account++ {
+		wb.Put(account, 100)
+	}
+	m.Commit(&wb)
+
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	for w := 0; w < 4; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r := rand.New(rand.NewSource(int64(w)))
+			for i := 0; i < 500; i++ {
+				// Each writer only moves amounts between its own accounts, so the balances it read are still current
+				// when it commits.
+				from := r.Intn(numOfAccounts/4)*4 + w
+				to := r.Intn(numOfAccounts/4)*4 + w
+				if from == to {
+					continue
+				}
+				reader := m.BeginRead()
+				fromBalance, _ := reader.Get(from)
+				toBalance, _ := reader.Get(to)
+				reader.Close()
+				var wb WriteBatch[int, int]
+				wb.Put(from, fromBalance-10)
+				wb.Put(to, toBalance+10)
+				m.Commit(&wb)
+			}
+		}()
+	}
+	var background sync.WaitGroup
+	background.Add(1)
+	go func() {
+		defer background.Done()
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+				m.GC()
+			}
+		}
+	}()
+	for reader := 0; reader < 4; reader++ {
+		background.Add(1)
+		go func() {
+			defer background.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				reader := m.BeginRead()
+				sum, accounts := 0, 0
+				for _, balance := range reader.All() {
+					sum += balance
+					accounts++
+				}
+				reader.Close()
+				assert.Equal(t, total, sum)
+				assert.Equal(t, numOfAccounts, accounts)
+			}
+		}()
+	}
+	wg.Wait()
+	close(stop)
+	background.Wait()
+
+	m.GC()
+	assert.Equal(t, numOfAccounts, countVersions(m))
+	checkTreeInvariants(t, m.tree)
+}
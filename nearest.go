@@ -0,0 +1,80 @@
+package btree
+
+// Floor returns the item with the biggest key that is smaller than or equal to the given key. The boolean is false
+// when there's no such item.
+func (b *Tree[K, V]) Floor(key K) (K, V, bool) {
+	return b.floor(key, true)
+}
+
+// Lower returns the item with the biggest key that is strictly smaller than the given key. The boolean is false when
+// there's no such item.
+func (b *Tree[K, V]) Lower(key K) (K, V, bool) {
+	return b.floor(key, false)
+}
+
+// Ceiling returns the item with the smallest key that is bigger than or equal to the given key. The boolean is false
+// when there's no such item.
+func (b *Tree[K, V]) Ceiling(key K) (K, V, bool) {
+	return b.ceiling(key, true)
+}
+
+// Higher returns the item with the smallest key that is strictly bigger than the given key. The boolean is false when
+// there's no such item.
+func (b *Tree[K, V]) Higher(key K) (K, V, bool) {
+	return b.ceiling(key, false)
+}
+
+// floor descends towards the key the same way findKey does. When the key isn't in a node, the insertion index tells
+// which item is the closest smaller one in this node. It's remembered as a candidate and the search continues in the
+// child before the insertion index, since every key in it is closer to the given key.
+func (b *Tree[K, V]) floor(key K, inclusive bool) (K, V, bool) {
+	var candidate *Item[K, V]
+	n := b.root
+	for {
+		found, index := n.findKey(key, b.search)
+		if found && inclusive {
+			return n.items[index].key, n.items[index].value, true
+		}
+		if index > 0 {
+			candidate = n.items[index-1]
+		}
+		if n.isLeaf() {
+			break
+		}
+		n = n.childNodes[index]
+	}
+	return itemOrZero(candidate)
+}
+
+// ceiling is the mirror image of floor. The item at the insertion index is the closest bigger one in the node. If the
+// key itself is in the node and isn't wanted, the search continues to the right of it.
+func (b *Tree[K, V]) ceiling(key K, inclusive bool) (K, V, bool) {
+	var candidate *Item[K, V]
+	n := b.root
+	for {
+		found, index := n.findKey(key, b.search)
+		if found {
+			if inclusive {
+				return n.items[index].key, n.items[index].value, true
+			}
+			index++
+		}
+		if index < len(n.items) {
+			candidate = n.items[index]
+		}
+		if n.isLeaf() {
+			break
+		}
+		n = n.childNodes[index]
+	}
+	return itemOrZero(candidate)
+}
+
+func itemOrZero[K any, V any](item *Item[K, V]) (K, V, bool) {
+	if item == nil {
+		var zeroKey K
+		var zeroValue V
+		return zeroKey, zeroValue, false
+	}
+	return item.key, item.value, true
+}
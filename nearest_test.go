@@ -0,0 +1,100 @@
+package btree
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type nearestResult struct {
+	key   int
+	found bool
+}
+
+func checkNearest(t *testing.T, lookup func(key int) (int, int, bool), expected map[int]nearestResult) {
+	t.Helper()
+	for key, result := range expected {
+		foundKey, value, found := lookup(key)
+		assert.Equal(t, result.found, found, "key %d", key)
+		if result.found {
+			assert.Equal(t, result.key, foundKey, "key %d", key)
+			assert.Equal(t, result.key, value, "key %d", key)
+		}
+	}
+}
+
+func Test_TreeFloor(t *testing.T) {
+	tree := newTestIntTree(100)
+	checkNearest(t, tree.Floor, map[int]nearestResult{
+		-1:  {0, false},
+		0:   {0, true},
+		1:   {0, true},
+		100: {100, true},
+		101: {100, true},
+		198: {198, true},
+		500: {198, true},
+	})
+}
+
+func Test_TreeLower(t *testing.T) {
+	tree := newTestIntTree(100)
+	checkNearest(t, tree.Lower, map[int]nearestResult{
+		0:   {0, false},
+		1:   {0, true},
+		2:   {0, true},
+		100: {98, true},
+		101: {100, true},
+		500: {198, true},
+	})
+}
+
+func Test_TreeCeiling(t *testing.T) {
+	tree := newTestIntTree(100)
+	checkNearest(t, tree.Ceiling, map[int]nearestResult{
+		-1:  {0, true},
+		0:   {0, true},
+		1:   {2, true},
+		100: {100, true},
+		101: {102, true},
+		198: {198, true},
+		199: {0, false},
+	})
+}
+
+func Test_TreeHigher(t *testing.T) {
+	tree := newTestIntTree(100)
+	checkNearest(t, tree.Higher, map[int]nearestResult{
+		-1:  {0, true},
+		0:   {2, true},
+		1:   {2, true},
+		100: {102, true},
+		101: {102, true},
+		196: {198, true},
+		198: {0, false},
+	})
+}
+
+func Test_TreeNearestEveryKey(t *testing.T) {
+	tree := newTestIntTree(100)
+
+	// Compare against the answer computed from the sorted keys for every key in and around the tree
+	for key := -1; key <= 200; key++ {
+		floor, _, _ := tree.Floor(key)
+		ceiling, _, _ := tree.Ceiling(key)
+		lower, _, _ := tree.Lower(key)
+		higher, _, _ := tree.Higher(key)
+
+		if key >= 0 && key <= 198 {
+			assert.Equal(t, key-key%2, floor, "floor %d", key)
+		}
+		if key >= 1 && key <= 199 {
+			assert.Equal(t, (key-1)-(key-1)%2, lower, "lower %d", key)
+		}
+		if key >= -1 && key <= 198 {
+			assert.Equal(t, key+(key+2)%2, ceiling, "ceiling %d", key)
+		}
+		if key >= -1 && key <= 197 {
+			assert.Equal(t, key+1+(key+3)%2, higher, "higher %d", key)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package btree
+
+// optimisticReadAttempts is the number of times Get tries to read the tree without latches before it falls back to
+// latch coupling, so that a reader can't be starved by a stream of writers changing the nodes it reads.
+const optimisticReadAttempts = 8
+
+// The readers of a LatchedTree can search it without taking any latch, using a version counter on every node. A writer
+// makes the version of a node odd before it changes the node and even again once it's done, so a reader that sees the
+// same even version before and after reading a node knows that no writer changed the node in between. If the version
+// changed, then the reader starts again from the root.
+//
+// A reader searches the node itself while a writer may be shifting its items, which is only safe in Go because the
+// items and children are kept in atomic slots. What the reader finds in the middle of a write may be a mix of the old
+// and the new items, but it's never used, since the reader validates the version after reading the node and before
+// trusting anything it found. No copy of the node is kept, so a write costs no more memory than with latches alone.
+//
+// A node is validated after the version of its child is read. This makes sure that the child was still the right one
+// to search when the reader got to it. Without it, a reader could get to a child just after a split moved the key to a
+// new sibling, and wrongly report that the key isn't in the tree.
+//
+//	reader:  v := p.version       search p       c := p.childNodes[i]       c.version       p.version == v ?
+//	writer:                          p.version++ (odd)       split c into c and s       p.version++
+
+// beginWrite marks the node as being changed, which makes optimistic readers that read it start again. It's called by
+// the writer that holds the latch of the node, so the version can't be changed by anyone else in between.
+func (n *latchedNode[K, V]) beginWrite() {
+	if n.version.Load()%2 == 0 {
+		n.version.Add(1)
+	}
+}
+
+// endWrite marks the node as unchanged again. New nodes, such as the siblings created by a split, start with an even
+// version, since they are complete by the time a parent makes them visible to readers.
+func (n *latchedNode[K, V]) endWrite() {
+	if n.version.Load()%2 == 1 {
+		n.version.Add(1)
+	}
+}
+
+// stableVersion returns the version of the node, or false if a writer is changing it.
+func (n *latchedNode[K, V]) stableVersion() (uint64, bool) {
+	version := n.version.Load()
+	return version, version%2 == 0
+}
+
+// getOptimistic searches the tree for the key without taking any latch. The last boolean is false if a writer changed
+// one of the nodes during the search, in which case the result has to be ignored.
+func (l *LatchedTree[K, V]) getOptimistic(key K) (V, bool, bool) {
+	var zero V
+	n := l.root.Load()
+	version, ok := n.stableVersion()
+	// The root itself may have been replaced by a split or a collapse of the root after it was loaded
+	if !ok || l.root.Load() != n {
+		return zero, false, false
+	}
+	for {
+		found, index := l.findKey(n, key)
+		if found || n.isLeaf() {
+			var value V
+			if found {
+				// The item may have been moved away since it was found, which the version shows below
+				item := n.items.get(index)
+				if item == nil {
+					return zero, false, false
+				}
+				value = item.value
+			}
+			return value, found, n.version.Load() == version
+		}
+
+		child := n.childNodes.get(index)
+		if child == nil {
+			return zero, false, false
+		}
+		childVersion, ok := child.stableVersion()
+		if !ok || n.version.Load() != version {
+			return zero, false, false
+		}
+		n, version = child, childVersion
+	}
+}
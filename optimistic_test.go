@@ -0,0 +1,170 @@
+package btree
+
+import (
+	"fmt"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_LatchedTreeGetOptimistic(t *testing.T) {
+	tree := NewLatchedTree[int, int](minItems)
+	for i := 0; i < 1000; i += 2 {
+		tree.Put(i, i*10)
+	}
+	for i := 0; i < 1000; i += 3 {
+		tree.Delete(i)
+	}
+
+	for i := 0; i < 1000; i++ {
+		value, found, ok := tree.getOptimistic(i)
+		require.True(t, ok)
+		require.Equal(t, i%2 == 0 && i%3 != 0, found, "key %d", i)
+		if found {
+			require.Equal(t, i*10, value)
+		}
+	}
+}
+
+func Test_LatchedTreeGetFallsBackToLatches(t *testing.T) {
+	tree := NewLatchedTree[int, int](minItems)
+	for i := 0; i < 100; i++ {
+		tree.Put(i, i)
+	}
+
+	// A node that is being changed forever makes every optimistic read fail
+	leaf := tree.root.Load()
+	for !leaf.isLeaf() {
+		leaf = leaf.childNodes.get(0)
+	}
+	leaf.beginWrite()
+	_, _, ok := tree.getOptimistic(0)
+	assert.False(t, ok)
+	value, found := tree.Get(0)
+	assert.True(t, found)
+	assert.Equal(t, 0, value)
+
+	leaf.endWrite()
+	_, _, ok = tree.getOptimistic(0)
+	assert.True(t, ok)
+}
+
+// Test_LatchedTreeRemoveFromInternalNodeChangesVersions removes a key from an internal node whose predecessor is
+// below a safe node. A reader may have passed the internal node before the predecessor moved up into it and get to the
+// leaf after that, so every node it passes on the way from the internal node to the leaf must get a new version for
+// the reader to notice the move and start again. Otherwise it would report the predecessor as missing.
+func Test_LatchedTreeRemoveFromInternalNodeChangesVersions(t *testing.T) {
+	tree := NewLatchedTree[int, int](1)
+	for _, key := range rand.New(rand.NewSource(1)).Perm(1000) {
+		tree.Put(key, key)
+	}
+
+	// Look for a key whose path to its predecessor passes a safe node
+	var key int
+	var path []*latchedNode[int, int]
+	var find func(n *latchedNode[int, int]) bool
+	find = func(n *latchedNode[int, int]) bool {
+		if n.isLeaf() {
+			return false
+		}
+		for i := 0; i < n.items.len(); i++ {
+			key = n.items.get(i).key
+			path = []*latchedNode[int, int]{n}
+			for child := n.childNodes.get(i); ; child = child.childNodes.get(child.childNodes.len() - 1) {
+				path = append(path, child)
+				if child.isLeaf() {
+					break
+				}
+			}
+			for _, node := range path[1 : len(path)-1] {
+				if node.items.len() > tree.minItems {
+					return true
+				}
+			}
+		}
+		for i := 0; i < n.childNodes.len(); i++ {
+			if find(n.childNodes.get(i)) {
+				return true
+			}
+		}
+		return false
+	}
+	require.True(t, find(tree.root.Load()))
+
+	leaf := path[len(path)-1]
+	predecessor := leaf.items.get(leaf.items.len() - 1).key
+	versions := make([]uint64, len(path))
+	for i, n := range path {
+		versions[i], _ = n.stableVersion()
+	}
+
+	require.True(t, tree.Delete(key))
+	for i, n := range path {
+		assert.NotEqual(t, versions[i], n.version.Load(), "node %d on the path", i)
+	}
+	value, found := tree.Get(predecessor)
+	assert.True(t, found)
+	assert.Equal(t, predecessor, value)
+	checkLatchedTreeInvariants(t, tree)
+}
+
+// Test_LatchedTreeOptimisticReadersDontMissKeys keeps a set of keys in the tree while writers add and remove other keys
+// around them, which keeps splitting, rotating and merging the nodes that hold them. A reader that got to a node just
+// after its keys moved elsewhere would report a key as missing. It's meant to be run with the race detector as well.
+func Test_LatchedTreeOptimisticReadersDontMissKeys(t *testing.T) {
+	for _, minItemsInNode := range []int{1, 2, 8} {
+		t.Run(fmt.Sprintf("minItems=%d", minItemsInNode), func(t *testing.T) {
+			const keySpace = 4000
+			tree := NewLatchedTree[int, int](minItemsInNode)
+			// The keys divisible by 4 are never removed
+			for key := 0; key < keySpace; key += 4 {
+				tree.Put(key, key)
+			}
+
+			var writers sync.WaitGroup
+			var done atomic.Bool
+			for w := 0; w < 4; w++ {
+				writers.Add(1)
+				go func() {
+					defer writers.Done()
+					r := rand.New(rand.NewSource(int64(w)))
+					for i := 0; i < 20000; i++ {
+						// The writers only add and remove keys that aren't divisible by 4
+						key := r.Intn(keySpace/4)*4 + r.Intn(3) + 1
+						if r.Intn(2) == 0 {
+							tree.Put(key, key)
+						} else {
+							tree.Delete(key)
+						}
+					}
+				}()
+			}
+			var readers sync.WaitGroup
+			for reader := 0; reader < 4; reader++ {
+				readers.Add(1)
+				go func() {
+					defer readers.Done()
+					r := rand.New(rand.NewSource(int64(reader)))
+					for !done.Load() {
+						key := r.Intn(keySpace/4) * 4
+						value, found := tree.Get(key)
+						assert.True(t, found, "key %d", key)
+						assert.Equal(t, key, value)
+						if value, found, ok := tree.getOptimistic(key); ok {
+							assert.True(t, found, "key %d", key)
+							assert.Equal(t, key, value)
+						}
+					}
+				}()
+			}
+			writers.Wait()
+			done.Store(true)
+			readers.Wait()
+			checkLatchedTreeInvariants(t, tree)
+		})
+	}
+}
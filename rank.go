@@ -0,0 +1,53 @@
+package btree
+
+// Rank returns the number of keys in the tree that are smaller than the given key. The key doesn't have to be in the
+// tree. The counts kept in the nodes are used to skip the subtrees to the left of the path to the key, so it takes
+// O(log n) instead of visiting all the smaller keys.
+func (b *Tree[K, V]) Rank(key K) int {
+	rank := 0
+	n := b.root
+	for {
+		found, index := n.findKey(key, b.search)
+		// All the items before the index and the children to their left are smaller than the key
+		rank += index
+		if n.isLeaf() {
+			return rank
+		}
+		for _, child := range n.childNodes[:index] {
+			rank += child.count
+		}
+		if found {
+			// Everything in the child to the left of the key is smaller as well
+			return rank + n.childNodes[index].count
+		}
+		n = n.childNodes[index]
+	}
+}
+
+// Select returns the item at the given position in the sorted order of the keys, where 0 is the smallest key. It's the
+// inverse of Rank. The boolean is false when the position is out of range.
+func (b *Tree[K, V]) Select(position int) (K, V, bool) {
+	if position < 0 || position >= b.root.count {
+		return itemOrZero[K, V](nil)
+	}
+	n := b.root
+	for {
+		if n.isLeaf() {
+			item := n.items[position]
+			return item.key, item.value, true
+		}
+		// Skip the children and items before the position. The i-th child is followed by the i-th item.
+		for i, child := range n.childNodes {
+			if position < child.count {
+				n = child
+				break
+			}
+			position -= child.count
+			if position == 0 {
+				item := n.items[i]
+				return item.key, item.value, true
+			}
+			position--
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package btree
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_TreeRank(t *testing.T) {
+	tree := newTestIntTree(100)
+
+	for key := -1; key <= 200; key++ {
+		// The keys are 0, 2, ..., 198, so (key+1)/2 of them are smaller than key
+		expected := (key + 1) / 2
+		if key < 0 {
+			expected = 0
+		}
+		require.Equal(t, expected, tree.Rank(key), "key %d", key)
+	}
+
+	assert.Equal(t, 0, NewTree[int, int](minItems).Rank(5))
+}
+
+func Test_TreeSelect(t *testing.T) {
+	tree := newTestIntTree(100)
+
+	for position := 0; position < 100; position++ {
+		key, value, found := tree.Select(position)
+		require.True(t, found)
+		require.Equal(t, position*2, key)
+		require.Equal(t, position*2, value)
+		require.Equal(t, position, tree.Rank(key))
+	}
+
+	_, _, found := tree.Select(-1)
+	assert.False(t, found)
+	_, _, found = tree.Select(100)
+	assert.False(t, found)
+}
+
+func Test_TreeRankAndSelectAfterChanges(t *testing.T) {
+	const numOfElements = 1000
+	r := rand.New(rand.NewSource(1))
+	tree := NewTree[int, int](minItems)
+	for _, key := range r.Perm(numOfElements) {
+		tree.Put(key, key)
+	}
+
+	// Remove the odd keys in random order, going through rotations and merges
+	for _, key := range r.Perm(numOfElements) {
+		if key%2 == 1 {
+			tree.Delete(key)
+		}
+	}
+	tree.DeleteMin()
+	tree.DeleteMax()
+	checkTreeInvariants(t, tree)
+
+	// The remaining keys are 2, 4, ..., 996
+	for position := 0; position < numOfElements/2-2; position++ {
+		key, _, found := tree.Select(position)
+		require.True(t, found)
+		require.Equal(t, (position+1)*2, key)
+		require.Equal(t, position, tree.Rank(key))
+	}
+}
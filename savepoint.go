@@ -0,0 +1,53 @@
+package btree
+
+import "errors"
+
+// ErrInvalidSavepoint is returned by RollbackTo when the savepoint doesn't belong to the transaction, or was discarded
+// by rolling back to an older savepoint.
+var ErrInvalidSavepoint = errors.New("btree: savepoint is not valid in this transaction")
+
+// Savepoint is a point inside a writable transaction that the transaction can be rolled back to, undoing only the
+// changes that were made after it.
+type Savepoint[K any, V any] struct {
+	// tree is a clone of the tree of the transaction at the savepoint. It's never changed, so it can be rolled back to
+	// any number of times.
+	tree *Tree[K, V]
+}
+
+// Savepoint marks the current state of the transaction so that it can be restored later with RollbackTo. Like
+// Begin, it clones the tree of the transaction in O(1), and the changes made after it copy only the nodes they change.
+// Savepoints can be nested, which allows a step of a long transaction to be undone when it fails without aborting the
+// whole transaction.
+//
+//	tx := tree.Begin(true)
+//	for _, record := range records {
+//		sp, _ := tx.Savepoint()
+//		if err := importRecord(tx, record); err != nil {
+//			tx.RollbackTo(sp) // Skip the bad record only
+//		}
+//	}
+//	tx.Commit()
+func (tx *Tx[K, V]) Savepoint() (*Savepoint[K, V], error) {
+	if err := tx.checkWritable(); err != nil {
+		return nil, err
+	}
+	sp := &Savepoint[K, V]{tree: tx.tree.Clone()}
+	tx.savepoints = append(tx.savepoints, sp)
+	return sp, nil
+}
+
+// RollbackTo undoes all the changes that were made in the transaction after the savepoint. The savepoint stays valid,
+// so it can be rolled back to again, but the savepoints that were taken after it are discarded, like in SQL.
+func (tx *Tx[K, V]) RollbackTo(sp *Savepoint[K, V]) error {
+	if err := tx.checkWritable(); err != nil {
+		return err
+	}
+	for i := len(tx.savepoints) - 1; i >= 0; i-- {
+		if tx.savepoints[i] == sp {
+			tx.savepoints = tx.savepoints[:i+1]
+			tx.tree = sp.tree.Clone()
+			return nil
+		}
+	}
+	return ErrInvalidSavepoint
+}
@@ -0,0 +1,86 @@
+package btree
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_TxRollbackToSavepoint(t *testing.T) {
+	tree := newTestIntTree(10)
+	tx := tree.Begin(true)
+	require.NoError(t, tx.Put(1, 1))
+
+	sp, err := tx.Savepoint()
+	require.NoError(t, err)
+	require.NoError(t, tx.Put(3, 3))
+	_, err = tx.Delete(1)
+	require.NoError(t, err)
+
+	require.NoError(t, tx.RollbackTo(sp))
+	_, found := tx.Get(3)
+	assert.False(t, found)
+	_, found = tx.Get(1)
+	assert.True(t, found)
+
+	// The savepoint can be rolled back to again
+	require.NoError(t, tx.Put(5, 5))
+	require.NoError(t, tx.RollbackTo(sp))
+	_, found = tx.Get(5)
+	assert.False(t, found)
+
+	require.NoError(t, tx.Commit())
+	assert.Equal(t, append([]int{0, 1}, keyRange(2, 20, 2)...), collectKeys(tree.All()))
+	checkTreeInvariants(t, tree)
+}
+
+func Test_TxNestedSavepoints(t *testing.T) {
+	tree := NewTree[int, int](minItems)
+	tx := tree.Begin(true)
+
+	var savepoints []*Savepoint[int, int]
+	for i := 0; i < 50; i++ {
+		sp, err := tx.Savepoint()
+		require.NoError(t, err)
+		savepoints = append(savepoints, sp)
+		require.NoError(t, tx.Put(i, i))
+	}
+
+	// Rolling back to a savepoint undoes the writes after it, including the ones in nested savepoints
+	require.NoError(t, tx.RollbackTo(savepoints[30]))
+	assert.Equal(t, keyRange(0, 30, 1), collectKeys(tx.All()))
+	assert.Equal(t, 30, tx.Len())
+
+	// The savepoints after it were discarded, and the ones before it are still valid
+	assert.ErrorIs(t, tx.RollbackTo(savepoints[40]), ErrInvalidSavepoint)
+	require.NoError(t, tx.RollbackTo(savepoints[10]))
+	assert.Equal(t, keyRange(0, 10, 1), collectKeys(tx.All()))
+
+	require.NoError(t, tx.Commit())
+	assert.Equal(t, keyRange(0, 10, 1), collectKeys(tree.All()))
+	checkTreeInvariants(t, tree)
+}
+
+func Test_TxSavepointErrors(t *testing.T) {
+	tree := newTestIntTree(10)
+
+	readTx := tree.Begin(false)
+	_, err := readTx.Savepoint()
+	assert.ErrorIs(t, err, ErrTxReadOnly)
+	readTx.Rollback()
+
+	tx := tree.Begin(true)
+	other := tree.Begin(true)
+	sp, err := other.Savepoint()
+	require.NoError(t, err)
+	assert.ErrorIs(t, tx.RollbackTo(sp), ErrInvalidSavepoint)
+
+	sp, err = tx.Savepoint()
+	require.NoError(t, err)
+	tx.Rollback()
+	assert.ErrorIs(t, tx.RollbackTo(sp), ErrTxClosed)
+	_, err = tx.Savepoint()
+	assert.ErrorIs(t, err, ErrTxClosed)
+	other.Rollback()
+}
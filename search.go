@@ -0,0 +1,45 @@
+package btree
+
+import "slices"
+
+// SearchStrategy selects how the items inside a single node are searched.
+type SearchStrategy int
+
+const (
+	// BinarySearch halves the searched items on every comparison. It's the default since it needs O(log(maxItems))
+	// comparisons per node.
+	BinarySearch SearchStrategy = iota
+	// LinearSearch compares the items one after the other. It needs more comparisons, but its access pattern is
+	// predictable, so it's often faster for small nodes where all the items share a few cache lines.
+	LinearSearch
+)
+
+// SetSearchStrategy changes the way keys are searched inside the nodes of the tree. It doesn't change the layout of the
+// tree, so it can be called at any time. It only affects this tree, including the nodes it shares with its clones.
+func (b *Tree[K, V]) SetSearchStrategy(strategy SearchStrategy) {
+	b.search = strategy
+}
+
+// linearSearch iterates all the items and finds the key. It stops at the first item that is bigger than the key since
+// the items are sorted.
+func (n *Node[K, V]) linearSearch(key K) (bool, int) {
+	for i, existingItem := range n.items {
+		res := n.config.compare(key, existingItem.key)
+		if res == 0 {
+			return true, i
+		}
+
+		if res < 0 {
+			return false, i
+		}
+	}
+	return false, len(n.items)
+}
+
+// binarySearch finds the key by repeatedly halving the range of items that may contain it.
+func (n *Node[K, V]) binarySearch(key K) (bool, int) {
+	index, found := slices.BinarySearchFunc(n.items, key, func(item *Item[K, V], key K) int {
+		return n.config.compare(item.key, key)
+	})
+	return found, index
+}
@@ -0,0 +1,74 @@
+package btree
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_NodeSearchStrategies(t *testing.T) {
+	root := newEmptyNode[string, string]()
+	newTreeWithRoot(root, minItems, strings.Compare)
+	addItems(root, "1", "3", "5", "7")
+
+	for _, strategy := range []SearchStrategy{BinarySearch, LinearSearch} {
+		for key, expected := range map[string]struct {
+			found bool
+			index int
+		}{
+			"0": {false, 0},
+			"1": {true, 0},
+			"4": {false, 2},
+			"7": {true, 3},
+			"8": {false, 4},
+		} {
+			found, index := root.findKey(key, strategy)
+			assert.Equal(t, expected.found, found, "strategy %d key %s", strategy, key)
+			assert.Equal(t, expected.index, index, "strategy %d key %s", strategy, key)
+		}
+	}
+}
+
+func Test_TreeLinearSearch(t *testing.T) {
+	tree := NewTree[int, int](minItems)
+	tree.SetSearchStrategy(LinearSearch)
+	for _, key := range rand.New(rand.NewSource(1)).Perm(500) {
+		tree.Put(key, key)
+	}
+	checkTreeInvariants(t, tree)
+
+	for key := 0; key < 500; key++ {
+		value, found := tree.Get(key)
+		require.True(t, found)
+		require.Equal(t, key, value)
+	}
+}
+
+// BenchmarkSearchStrategy compares both strategies for different node sizes. Linear search tends to win for small
+// nodes and binary search for big ones.
+func BenchmarkSearchStrategy(b *testing.B) {
+	const numOfElements = 100000
+	keys := rand.New(rand.NewSource(1)).Perm(numOfElements)
+
+	for _, minItemsInNode := range []int{2, 4, 8, 16, 32, 64, 128} {
+		tree := NewTree[int, int](minItemsInNode)
+		for _, key := range keys {
+			tree.Put(key, key)
+		}
+		for _, strategy := range []struct {
+			name     string
+			strategy SearchStrategy
+		}{{"linear", LinearSearch}, {"binary", BinarySearch}} {
+			b.Run(fmt.Sprintf("minItems=%d/%s", minItemsInNode, strategy.name), func(b *testing.B) {
+				tree.SetSearchStrategy(strategy.strategy)
+				for i := 0; i < b.N; i++ {
+					tree.Get(keys[i%numOfElements])
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package btree
+
+import (
+	"errors"
+	"iter"
+)
+
+// ErrTxClosed is returned when a transaction is used to write after it was committed or rolled back.
+var ErrTxClosed = errors.New("btree: transaction is closed")
+
+// ErrTxReadOnly is returned when a read-only transaction is used to write or is committed.
+var ErrTxReadOnly = errors.New("btree: transaction is read-only")
+
+// ErrTxConflict is returned by Commit when the tree was changed after the transaction began, in which case none of
+// the changes of the transaction are applied.
+var ErrTxConflict = errors.New("btree: tree was changed since the transaction began")
+
+// Tx is a transaction over a tree. It works on a clone of the tree taken when it began, so it sees the tree as it was
+// at that point, together with its own changes. Nothing it does is visible outside of it until it's committed, and
+// committing publishes all of its changes at once by replacing the root of the tree with the root of the clone. Since
+// the clone copies every node it changes (see Clone), a transaction that is rolled back, or that is simply abandoned,
+// leaves the tree exactly as it was, without any half-split node. A transaction holds no lock while it's open, so
+// abandoning one doesn't block anything either, and it's garbage collected together with its clone.
+//
+// A transaction isn't safe for concurrent use itself, but any number of transactions can be open on the same tree.
+type Tx[K any, V any] struct {
+	tree     *Tree[K, V]
+	writable bool
+	closed   bool
+	// commit publishes the given tree as the result of the transaction. It depends on the kind of tree the transaction
+	// began on.
+	commit func(result *Tree[K, V]) error
+	// savepoints are the savepoints of the transaction that can still be rolled back to, from the oldest.
+	savepoints []*Savepoint[K, V]
+}
+
+// Begin starts a transaction on the tree. A read-only transaction sees the tree as it was when it began, no matter how
+// the tree is changed afterwards. A writable transaction can change it as well. Taking the clone is O(1).
+//
+// Commit fails with ErrTxConflict if the tree was changed after the transaction began, either directly or by another
+// transaction that was committed first.
+func (b *Tree[K, V]) Begin(writable bool) *Tx[K, V] {
+	base := b.modifications
+	clone := b.Clone()
+	return &Tx[K, V]{
+		tree:     clone,
+		writable: writable,
+		commit: func(result *Tree[K, V]) error {
+			return b.commitTx(base, result)
+		},
+	}
+}
+
+// commitTx replaces the content of the tree with the result of a transaction that began when the modification counter
+// of the tree was base. The result is a clone of the tree or a clone of such a clone. Every write that changes the tree
+// increments the counter, so the tree was changed since then if and only if the counter isn't base anymore. Writes that
+// change nothing, such as deleting a missing key, don't increment it, so they don't conflict with the transaction.
+func (b *Tree[K, V]) commitTx(base uint64, result *Tree[K, V]) error {
+	if b.modifications != base {
+		return ErrTxConflict
+	}
+	// The tree and the result keep different owners, so the nodes they share from now on are copied by whichever of
+	// them changes them first.
+	b.root = result.root
+	b.nodeCount = result.nodeCount
+	b.modifications++
+	return nil
+}
+
+// Writable reports whether the transaction can change the tree.
+func (tx *Tx[K, V]) Writable() bool {
+	return tx.writable
+}
+
+// Commit publishes all the changes of the transaction and closes it. If the commit fails, then the transaction is
+// closed as well, and the tree is left untouched.
+func (tx *Tx[K, V]) Commit() error {
+	if err := tx.checkWritable(); err != nil {
+		return err
+	}
+	err := tx.commit(tx.tree)
+	tx.close()
+	return err
+}
+
+// Rollback discards all the changes of the transaction and closes it. Rolling back a closed transaction does nothing.
+func (tx *Tx[K, V]) Rollback() {
+	if !tx.closed {
+		tx.close()
+	}
+}
+
+func (tx *Tx[K, V]) close() {
+	tx.closed = true
+	tx.savepoints = nil
+}
+
+func (tx *Tx[K, V]) checkWritable() error {
+	if tx.closed {
+		return ErrTxClosed
+	}
+	if !tx.writable {
+		return ErrTxReadOnly
+	}
+	return nil
+}
+
+// Get returns the value stored under the given key, as seen by the transaction. The boolean is false when the key
+// isn't in the tree. After the transaction is closed, it keeps seeing the tree as it was when it was closed.
+func (tx *Tx[K, V]) Get(key K) (V, bool) {
+	return tx.tree.Get(key)
+}
+
+// Len returns the number of items in the tree, as seen by the transaction.
+func (tx *Tx[K, V]) Len() int {
+	return tx.tree.Len()
+}
+
+// Put adds a key to the tree, or replaces the value if the key is already in the tree. The change is visible only to
+// the transaction until it's committed.
+func (tx *Tx[K, V]) Put(key K, value V) error {
+	if err := tx.checkWritable(); err != nil {
+		return err
+	}
+	tx.tree.Put(key, value)
+	return nil
+}
+
+// Delete removes a key from the tree. It reports whether the key was in the tree. The change is visible only to the
+// transaction until it's committed.
+func (tx *Tx[K, V]) Delete(key K) (bool, error) {
+	if err := tx.checkWritable(); err != nil {
+		return false, err
+	}
+	return tx.tree.Delete(key), nil
+}
+
+// Cursor creates a cursor over the tree, as seen by the transaction. Like a cursor over a tree, it's invalidated by
+// the writes of the transaction.
+func (tx *Tx[K, V]) Cursor() *Cursor[K, V] {
+	return tx.tree.Cursor()
+}
+
+// All returns an iterator over all the items in ascending key order, as seen by the transaction.
+func (tx *Tx[K, V]) All() iter.Seq2[K, V] {
+	return tx.tree.All()
+}
+
+// Range returns an iterator over the items with greaterOrEqual <= key < lessThan in ascending key order, as seen by the
+// transaction.
+func (tx *Tx[K, V]) Range(greaterOrEqual, lessThan K) iter.Seq2[K, V] {
+	return tx.tree.Range(greaterOrEqual, lessThan)
+}
@@ -0,0 +1,260 @@
+package btree
+
+import (
+	"math/rand"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_TxCommit(t *testing.T) {
+	tree := newTestIntTree(100)
+	tx := tree.Begin(true)
+	require.NoError(t, tx.Put(1, 1))
+	deleted, err := tx.Delete(0)
+	require.NoError(t, err)
+	assert.True(t, deleted)
+
+	// The changes are visible inside the transaction only
+	value, found := tx.Get(1)
+	assert.True(t, found)
+	assert.Equal(t, 1, value)
+	_, found = tree.Get(1)
+	assert.False(t, found)
+	_, found = tree.Get(0)
+	assert.True(t, found)
+
+	require.NoError(t, tx.Commit())
+	value, found = tree.Get(1)
+	assert.True(t, found)
+	assert.Equal(t, 1, value)
+	_, found = tree.Get(0)
+	assert.False(t, found)
+	assert.Equal(t, 100, tree.Len())
+	checkTreeInvariants(t, tree)
+
+	// The transaction is closed and keeps seeing the tree as it was committed
+	assert.ErrorIs(t, tx.Put(2, 2), ErrTxClosed)
+	assert.ErrorIs(t, tx.Commit(), ErrTxClosed)
+	tree.Put(3, 3)
+	_, found = tx.Get(3)
+	assert.False(t, found)
+}
+
+func Test_TxRollback(t *testing.T) {
+	tree := newTestIntTree(100)
+	tx := tree.Begin(true)
+	// Enough writes to split and merge nodes
+	for i := 0; i < 100; i++ {
+		require.NoError(t, tx.Put(i*2+1, i))
+		_, err := tx.Delete(i * 2)
+		require.NoError(t, err)
+	}
+	tx.Rollback()
+	tx.Rollback()
+
+	assert.Equal(t, keyRange(0, 200, 2), collectKeys(tree.All()))
+	checkTreeInvariants(t, tree)
+	_, err := tx.Delete(0)
+	assert.ErrorIs(t, err, ErrTxClosed)
+}
+
+func Test_TxReadOnly(t *testing.T) {
+	tree := newTestIntTree(10)
+	tx := tree.Begin(false)
+	assert.False(t, tx.Writable())
+	assert.ErrorIs(t, tx.Put(1, 1), ErrTxReadOnly)
+	_, err := tx.Delete(0)
+	assert.ErrorIs(t, err, ErrTxReadOnly)
+	assert.ErrorIs(t, tx.Commit(), ErrTxReadOnly)
+
+	// The transaction sees the tree as it was when it began
+	tree.Put(1, 1)
+	tree.Delete(0)
+	assert.Equal(t, keyRange(0, 20, 2), collectKeys(tx.All()))
+	assert.Equal(t, []int{4, 6}, collectKeys(tx.Range(3, 7)))
+	cursor := tx.Cursor()
+	assert.True(t, cursor.First())
+	assert.Equal(t, 0, cursor.Key())
+	assert.Equal(t, 10, tx.Len())
+	tx.Rollback()
+}
+
+func Test_TxConflict(t *testing.T) {
+	tree := newTestIntTree(10)
+	first := tree.Begin(true)
+	second := tree.Begin(true)
+	require.NoError(t, first.Put(1, 1))
+	require.NoError(t, second.Put(3, 3))
+
+	require.NoError(t, first.Commit())
+	assert.ErrorIs(t, second.Commit(), ErrTxConflict)
+	_, found := tree.Get(3)
+	assert.False(t, found)
+
+	// A direct write conflicts as well
+	tx := tree.Begin(true)
+	require.NoError(t, tx.Put(5, 5))
+	tree.Put(7, 7)
+	assert.ErrorIs(t, tx.Commit(), ErrTxConflict)
+	_, found = tree.Get(5)
+	assert.False(t, found)
+
+	// A transaction that began after the writes can be committed
+	tx = tree.Begin(true)
+	require.NoError(t, tx.Put(5, 5))
+	require.NoError(t, tx.Commit())
+	_, found = tree.Get(5)
+	assert.True(t, found)
+	checkTreeInvariants(t, tree)
+}
+
+func Test_TxNoOpWritesDontConflict(t *testing.T) {
+	tree := newTestIntTree(250)
+	tx := tree.Begin(true)
+	require.NoError(t, tx.Put(1000, 1))
+
+	// None of these writes changes the tree, so none of them conflicts with the transaction
+	assert.Equal(t, 0, tree.DeleteRange(500, 600))
+	assert.Equal(t, 0, tree.DeleteRange(11, 12))
+	assert.Equal(t, 0, tree.DeleteRange(500, 400))
+	assert.False(t, tree.Delete(1001))
+	var wb WriteBatch[int, int]
+	wb.Delete(1001)
+	wb.Delete(1003)
+	tree.Apply(&wb)
+	tree.Apply(&WriteBatch[int, int]{})
+
+	require.NoError(t, tx.Commit())
+	value, found := tree.Get(1000)
+	assert.True(t, found)
+	assert.Equal(t, 1, value)
+	checkTreeInvariants(t, tree)
+
+	// Writes that do change the tree still conflict
+	tx = tree.Begin(true)
+	require.NoError(t, tx.Put(1001, 1))
+	wb.Delete(0)
+	tree.Apply(&wb)
+	assert.ErrorIs(t, tx.Commit(), ErrTxConflict)
+
+	tx = tree.Begin(true)
+	require.NoError(t, tx.Put(1001, 1))
+	assert.Equal(t, 1, tree.DeleteRange(1, 3))
+	assert.ErrorIs(t, tx.Commit(), ErrTxConflict)
+}
+
+func Test_TxAbandoned(t *testing.T) {
+	tree := newTestIntTree(100)
+	abandoned := tree.Begin(true)
+	for i := 0; i < 100; i++ {
+		require.NoError(t, abandoned.Put(i*2+1, i))
+	}
+
+	// The tree is left as it was and a later transaction can still commit
+	tx := tree.Begin(true)
+	require.NoError(t, tx.Put(1, 1))
+	require.NoError(t, tx.Commit())
+	assert.Equal(t, append([]int{0, 1}, keyRange(2, 200, 2)...), collectKeys(tree.All()))
+	checkTreeInvariants(t, tree)
+}
+
+func Test_ConcurrentTreeTxAbandoned(t *testing.T) {
+	tree := NewConcurrentTree[int, int](minItems)
+	abandoned := tree.Begin(true)
+	require.NoError(t, abandoned.Put(1, 1))
+
+	// Beginning another writable transaction doesn't wait for the abandoned one to be closed
+	done := make(chan error)
+	go func() {
+		tx := tree.Begin(true)
+		if err := tx.Put(2, 2); err != nil {
+			done <- err
+			return
+		}
+		done <- tx.Commit()
+	}()
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "Begin is blocked by the abandoned transaction")
+	}
+
+	// The abandoned transaction can't be committed anymore once the tree was changed
+	assert.ErrorIs(t, abandoned.Commit(), ErrTxConflict)
+	assert.Equal(t, []int{2}, collectKeys(tree.All()))
+}
+
+// Test_ConcurrentTreeTx moves amounts between accounts in transactions while readers sum all of them, like
+// Test_ConcurrentTreeSnapshotIsConsistent. It's meant to be run with the race detector as well.
+func Test_ConcurrentTreeTx(t *testing.T) {
+	const numOfAccounts = 200
+	const total = numOfAccounts * 100
+	tree := NewConcurrentTree[int, int](minItems)
+	tx := tree.Begin(true)
+	for account := 0; account < numOfAccounts; account++ {
+		require.NoError(t, tx.Put(account, 100))
+	}
+	require.NoError(t, tx.Commit())
+
+	var writers sync.WaitGroup
+	for w := 0; w < 4; w++ {
+		writers.Add(1)
+		go func() {
+			defer writers.Done()
+			r := rand.New(rand.NewSource(int64(w)))
+			for i := 0; i < 200; i++ {
+				for {
+					tx := tree.Begin(true)
+					for j := 0; j < 5; j++ {
+						from, to := r.Intn(numOfAccounts), r.Intn(numOfAccounts)
+						fromBalance, _ := tx.Get(from)
+						assert.NoError(t, tx.Put(from, fromBalance-10))
+						toBalance, _ := tx.Get(to)
+						assert.NoError(t, tx.Put(to, toBalance+10))
+					}
+					// Half of the transactions are rolled back and none of their changes is seen
+					if i%2 == 0 {
+						tx.Rollback()
+						break
+					}
+					// The others are retried until they commit, since the writers may conflict with each other
+					err := tx.Commit()
+					if err == nil {
+						break
+					}
+					assert.ErrorIs(t, err, ErrTxConflict)
+				}
+			}
+		}()
+	}
+	var readers sync.WaitGroup
+	for reader := 0; reader < 4; reader++ {
+		readers.Add(1)
+		go func() {
+			defer readers.Done()
+			for i := 0; i < 200; i++ {
+				tx := tree.Begin(false)
+				sum := 0
+				for _, balance := range tx.All() {
+					sum += balance
+				}
+				tx.Rollback()
+				assert.Equal(t, total, sum)
+			}
+		}()
+	}
+	writers.Wait()
+	readers.Wait()
+
+	sum := 0
+	for _, balance := range tree.All() {
+		sum += balance
+	}
+	assert.Equal(t, total, sum)
+	checkTreeInvariants(t, tree.tree)
+}